@@ -0,0 +1,72 @@
+package jsonrq
+
+import (
+	"time"
+)
+
+// BackoffStrategy computes the delay before a retry. Next is called with the
+// number of the failed attempt, starting at 1. It may be called concurrently.
+type BackoffStrategy interface {
+	Next(attempt int) time.Duration
+}
+
+// BackoffFunc adapts a function to a BackoffStrategy.
+type BackoffFunc func(attempt int) time.Duration
+
+// Next calls f.
+func (f BackoffFunc) Next(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// WithBackoffStrategy computes the delays between retries with s instead of
+// the exponential backoff configured with WithBackoff and WithJitter. A
+// Retry-After header asking for a longer delay still takes precedence.
+func WithBackoffStrategy(s BackoffStrategy) Option {
+	return func(w *worker) {
+		w.strategy = s
+	}
+}
+
+// ConstantBackoff waits d before every retry.
+func ConstantBackoff(d time.Duration) BackoffStrategy {
+	return BackoffFunc(func(int) time.Duration {
+		return d
+	})
+}
+
+// LinearBackoff waits step times the number of the failed attempt, but at most
+// max. A max of zero means no limit.
+func LinearBackoff(step, max time.Duration) BackoffStrategy {
+	return BackoffFunc(func(attempt int) time.Duration {
+		d := step * time.Duration(attempt)
+		if max > 0 && d > max {
+			d = max
+		}
+		return d
+	})
+}
+
+// ExponentialBackoff waits base*factor^(n-1) after the n-th failed attempt,
+// but at most max, like WithBackoff.
+func ExponentialBackoff(base time.Duration, factor float64, max time.Duration) BackoffStrategy {
+	return backoff{base: base, factor: factor, max: max}
+}
+
+// Next returns the delay after the given failed attempt, starting at 1.
+func (b backoff) Next(attempt int) time.Duration {
+	return b.delay(attempt - 1)
+}
+
+// retryDelay returns the time to wait after the given failed attempt, starting
+// at 0, that failed with err.
+func (w worker) retryDelay(attempt int, err error) time.Duration {
+	var s BackoffStrategy = w.backoff
+	if w.strategy != nil {
+		s = w.strategy
+	}
+	delay := s.Next(attempt + 1)
+	if ra := retryAfter(err); ra > delay {
+		delay = ra
+	}
+	return delay
+}
@@ -0,0 +1,177 @@
+package jsonrq
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBodyTooLarge is recorded if a response body exceeds the size set with
+// WithMaxBodySize.
+var ErrBodyTooLarge = errors.New("Response body too large")
+
+// WithMaxBodySize limits the size of the response bodies that are decoded to n
+// bytes.
+func WithMaxBodySize(n int64) Option {
+	return func(w *worker) {
+		w.maxBody = n
+	}
+}
+
+// limitedBody reads at most n bytes from r and fails with ErrBodyTooLarge if r
+// holds more data.
+type limitedBody struct {
+	r io.Reader
+	n int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.n <= 0 {
+		var probe [1]byte
+		n, err := b.r.Read(probe[:])
+		if n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > b.n {
+		p = p[:b.n]
+	}
+	n, err := b.r.Read(p)
+	b.n -= int64(n)
+	return n, err
+}
+
+// WithDecompression decodes response bodies according to their
+// Content-Encoding. This is only needed, if Accept-Encoding is set explicitly,
+// otherwise the transport already takes care of gzip. Supported encodings are
+// gzip, deflate and those added with WithDecompressor.
+func WithDecompression() Option {
+	return func(w *worker) {
+		w.decompress = true
+	}
+}
+
+// WithDecompressor enables WithDecompression and decodes responses with the
+// given Content-Encoding, e.g. br or zstd, with the reader returned by factory.
+// It takes precedence over the built-in support for an encoding.
+func WithDecompressor(encoding string, factory func(io.Reader) io.Reader) Option {
+	return func(w *worker) {
+		w.decompress = true
+		if w.decompressors == nil {
+			w.decompressors = make(map[string]func(io.Reader) io.Reader)
+		}
+		w.decompressors[strings.ToLower(encoding)] = factory
+	}
+}
+
+// decompress wraps body, read from resp, according to the Content-Encoding of
+// resp, using the decompressors registered with WithDecompressor first.
+func decompress(resp *http.Response, body io.Reader, custom map[string]func(io.Reader) io.Reader) (io.Reader, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if factory, ok := custom[encoding]; ok {
+		return factory(body), nil
+	}
+
+	switch encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		// deflate should be zlib wrapped, but raw deflate streams are
+		// common enough to accept them as well.
+		br := bufio.NewReader(body)
+		if h, err := br.Peek(2); err == nil && isZlibHeader(h) {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	default:
+		return nil, errors.Errorf("Unsupported Content-Encoding %q", resp.Header.Get("Content-Encoding"))
+	}
+}
+
+func isZlibHeader(h []byte) bool {
+	return h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0
+}
+
+// RawBodyReceiver can be implemented by a JSONRequest to receive the complete
+// response body, e.g. to compute a checksum, if the Pool was created
+// WithBufferBody.
+type RawBodyReceiver interface {
+	JSONRequest
+	SetRawBody(body []byte)
+}
+
+// WithBufferBody reads every successful response body into memory before
+// decoding it, and passes it to requests implementing RawBodyReceiver. This
+// costs memory for the whole body, so consider combining it with
+// WithMaxBodySize.
+func WithBufferBody() Option {
+	return func(w *worker) {
+		w.bufferBody = true
+	}
+}
+
+// bufferBody reads body into memory and passes it on to r, if it is a
+// RawBodyReceiver.
+func bufferBody(r JSONRequest, body io.Reader) (io.Reader, error) {
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if rr, ok := r.(RawBodyReceiver); ok {
+		rr.SetRawBody(b)
+	}
+	return bytes.NewReader(b), nil
+}
+
+// WithRawBodyOnError keeps up to n bytes of the response body and attaches
+// them to the *DecodeError if decoding fails.
+func WithRawBodyOnError(n int) Option {
+	return func(w *worker) {
+		w.rawBody = n
+	}
+}
+
+// capBuffer keeps the first n bytes written to it and discards the rest.
+type capBuffer struct {
+	n   int
+	buf []byte
+}
+
+func (b *capBuffer) Write(p []byte) (int, error) {
+	if room := b.n - len(b.buf); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		b.buf = append(b.buf, p[:room]...)
+	}
+	return len(p), nil
+}
+
+// full reports whether the buffer holds n bytes.
+func (b *capBuffer) full() bool {
+	return len(b.buf) >= b.n
+}
+
+// attachRawBody adds the body kept in raw to err, if it is a *DecodeError.
+// body is the reader teeing into raw; it is read further until raw is full.
+func attachRawBody(err error, raw *capBuffer, body io.Reader) {
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		return
+	}
+	if !raw.full() {
+		io.Copy(io.Discard, io.LimitReader(body, int64(raw.n-len(raw.buf))))
+	}
+	de.Body = raw.buf
+}
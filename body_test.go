@@ -0,0 +1,75 @@
+package jsonrq
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRedirectReplaysBody(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if r.Method != "POST" || string(b) != `{"x":1}` {
+			t.Errorf("got %s with body %q, want POST with the original body", r.Method, b)
+		}
+		w.Write([]byte(`{"ok":true}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	rq := NewTypedRequest[map[string]bool](srv.URL + "/old")
+	rq.SetMethod("POST")
+	rq.SetBody(map[string]int{"x": 1})
+	if err := Process(context.Background(), nil, rq); err != nil {
+		t.Fatal(err)
+	}
+	if !rq.Result()["ok"] {
+		t.Errorf("got %v, want the response of the redirect target", rq.Result())
+	}
+}
+
+func TestRetryReplaysSeekerBody(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if string(b) != "payload" {
+			t.Errorf("attempt %d: got body %q", atomic.LoadInt32(&attempts)+1, b)
+		}
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	f, err := os.CreateTemp(t.TempDir(), "body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString("xxpayload"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	rq := NewTypedRequest[map[string]int](srv.URL)
+	rq.SetMethod("PUT")
+	rq.SetBodyReader(f, "text/plain")
+	if err := Process(context.Background(), nil, rq, WithRetries(1)); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("got %d attempts, want 2", attempts)
+	}
+}
@@ -0,0 +1,62 @@
+package jsonrq
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBodyReadTimeout is recorded if no data of a response body arrived within
+// the time set with WithBodyReadTimeout.
+var ErrBodyReadTimeout = errors.New("Response body read timed out")
+
+// WithBodyReadTimeout aborts reading a response body once no data arrived for
+// d, e.g. because the server trickles the body. Unlike the Timeout of the
+// client, it doesn't limit the total time a large body may take.
+func WithBodyReadTimeout(d time.Duration) Option {
+	return func(w *worker) {
+		w.bodyTimeout = d
+	}
+}
+
+// idleBody closes the underlying body, which aborts a pending read, if no data
+// was read for d.
+type idleBody struct {
+	rc    io.ReadCloser
+	d     time.Duration
+	timer *time.Timer
+
+	mu       sync.Mutex
+	timedOut bool
+}
+
+func newIdleBody(rc io.ReadCloser, d time.Duration) *idleBody {
+	b := &idleBody{rc: rc, d: d}
+	b.timer = time.AfterFunc(d, func() {
+		b.mu.Lock()
+		b.timedOut = true
+		b.mu.Unlock()
+		rc.Close()
+	})
+	return b
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.timedOut {
+		return n, ErrBodyReadTimeout
+	}
+	if n > 0 {
+		b.timer.Reset(b.d)
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	return b.rc.Close()
+}
@@ -0,0 +1,74 @@
+package jsonrq
+
+import (
+	"sync"
+	"time"
+)
+
+// WithCircuitBreaker stops sending requests to a host after threshold
+// consecutive transient failures. For the following cooldown, requests to the
+// host fail immediately with a *CircuitOpenError. Afterwards a single probe
+// request is let through, which closes the circuit if it succeeds.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(w *worker) {
+		w.breaker = &breaker{
+			threshold: threshold,
+			cooldown:  cooldown,
+			hosts:     make(map[string]*circuit),
+		}
+	}
+}
+
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow returns an error if requests to host are not allowed.
+func (b *breaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.hosts[host]
+	if c == nil || c.failures < b.threshold {
+		return nil
+	}
+	if c.probing || time.Now().Before(c.openUntil) {
+		return &CircuitOpenError{Host: host, Until: c.openUntil}
+	}
+	c.probing = true
+	return nil
+}
+
+// done records the outcome of a request to host that was allowed.
+func (b *breaker) done(host string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.hosts[host]
+	if !IsRetryable(err) {
+		if c != nil {
+			delete(b.hosts, host)
+		}
+		return
+	}
+
+	if c == nil {
+		c = new(circuit)
+		b.hosts[host] = c
+	}
+	c.failures++
+	c.probing = false
+	if c.failures >= b.threshold {
+		c.openUntil = time.Now().Add(b.cooldown)
+	}
+}
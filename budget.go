@@ -0,0 +1,51 @@
+package jsonrq
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// WithBudget limits the response bytes read by all workers of the Pool to
+// maxBytes in total. Once the budget is used up, reading the current response
+// fails and later requests fail without being sent, both with a
+// *BudgetExceededError. Responses served from the cache are not counted.
+func WithBudget(maxBytes int64) Option {
+	return func(w *worker) {
+		b := &budget{max: maxBytes}
+		b.left.Store(maxBytes)
+		w.budget = b
+	}
+}
+
+// budget is shared by the workers of a Pool.
+type budget struct {
+	max  int64
+	left atomic.Int64
+}
+
+// check fails once the budget is used up.
+func (b *budget) check() error {
+	if b.left.Load() <= 0 {
+		return &BudgetExceededError{Budget: b.max}
+	}
+	return nil
+}
+
+// budgetBody deducts the bytes read from rc from the budget.
+type budgetBody struct {
+	rc io.ReadCloser
+	b  *budget
+}
+
+func (bb budgetBody) Read(p []byte) (int, error) {
+	n, err := bb.rc.Read(p)
+	if left := bb.b.left.Add(-int64(n)); left < 0 {
+		// Only pass on the bytes that were within the budget.
+		return max(n+int(left), 0), &BudgetExceededError{Budget: bb.b.max}
+	}
+	return n, err
+}
+
+func (bb budgetBody) Close() error {
+	return bb.rc.Close()
+}
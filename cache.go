@@ -0,0 +1,104 @@
+package jsonrq
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCache keeps the bodies of successful GET responses in memory for ttl and
+// replays them for later requests of the same URL, or with the same key if
+// they are Keyed. Every request decodes its own copy into Data(). Responses
+// with Cache-Control: no-store are not cached.
+// Requests with credentials, i.e. an Authorization or Cookie header or cookies
+// from the client's jar, neither use nor fill the cache, so responses are
+// never shared between different users.
+func WithCache(ttl time.Duration) Option {
+	return func(w *worker) {
+		w.cache = &cache{ttl: ttl, m: make(map[string]cacheEntry)}
+	}
+}
+
+// CacheStatusReceiver can be implemented by a JSONRequest to learn whether its
+// response was served from the cache enabled with WithCache.
+type CacheStatusReceiver interface {
+	JSONRequest
+	SetCacheStatus(hit bool)
+}
+
+// SetCacheStatus is called by the workers to record whether the response was
+// served from the cache.
+func (r *BasicRequest) SetCacheStatus(hit bool) {
+	r.fromCache = hit
+}
+
+// FromCache reports whether the response was served from the cache.
+func (r *BasicRequest) FromCache() bool {
+	return r.fromCache
+}
+
+type cache struct {
+	ttl time.Duration
+
+	mu sync.Mutex
+	m  map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	resp    *http.Response
+	body    []byte
+	expires time.Time
+}
+
+// get returns a copy of the cached response for key, or nil.
+func (c *cache) get(key string) *http.Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.m[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(e.expires) {
+		delete(c.m, key)
+		return nil
+	}
+	return replay(e.resp, e.body)
+}
+
+// store caches resp if it is cacheable and returns a response that can be read
+// in its place.
+func (c *cache) store(key string, resp *http.Response, maxBody int64) (*http.Response, error) {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || noStore(resp.Header) {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var body io.Reader = resp.Body
+	if maxBody > 0 {
+		body = &limitedBody{r: body, n: maxBody}
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.m[key] = cacheEntry{resp: resp, body: b, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return replay(resp, b), nil
+}
+
+func noStore(header http.Header) bool {
+	for _, v := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+				return true
+			}
+		}
+	}
+	return false
+}
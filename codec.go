@@ -0,0 +1,40 @@
+package jsonrq
+
+import (
+	"io"
+)
+
+// Codec encodes and decodes JSON, e.g. with a faster library than
+// encoding/json.
+type Codec interface {
+	Decode(r io.Reader, v interface{}) error
+	Encode(w io.Writer, v interface{}) error
+}
+
+// WithCodec uses c instead of encoding/json to decode responses into Data and
+// to encode bodies set with SetBody. WithDecoderConfig and WithEncoderConfig
+// don't apply to c. A StreamRequest is still passed a *json.Decoder.
+func WithCodec(c Codec) Option {
+	return func(w *worker) {
+		w.codec = c
+		w.encoderConfig().codec = c
+	}
+}
+
+// encoderConfig returns the Pool's encoder configuration, creating it from
+// the defaults if needed.
+func (w *worker) encoderConfig() *encoderConfig {
+	if w.encoder == nil {
+		c := defaultEncoderConfig
+		w.encoder = &c
+	}
+	return w.encoder
+}
+
+// decodeJSON decodes body into v with the Pool's codec or configuration.
+func (w worker) decodeJSON(body io.Reader, v interface{}) error {
+	if w.codec != nil {
+		return w.codec.Decode(body, v)
+	}
+	return w.jsonDecoder(body).Decode(v)
+}
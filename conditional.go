@@ -0,0 +1,64 @@
+package jsonrq
+
+import (
+	"net/http"
+	"time"
+)
+
+// ConditionalRequest can be implemented by a JSONRequest to perform conditional
+// requests. If ETag returns a non-empty value, it is sent as If-None-Match. The
+// ETag of every response is passed to SetETag. A 304 Not Modified response is
+// not decoded, and reported with SetNotModified instead of an error.
+type ConditionalRequest interface {
+	JSONRequest
+	ETag() string
+	SetETag(etag string)
+	SetNotModified(notModified bool)
+}
+
+// ModifiedSinceRequest can be implemented by a JSONRequest to perform
+// time-based conditional requests. If LastModified returns a non-zero time, it
+// is sent as If-Modified-Since. The Last-Modified time of every response is
+// passed to SetLastModified. A 304 Not Modified response is handled as for a
+// ConditionalRequest.
+type ModifiedSinceRequest interface {
+	JSONRequest
+	LastModified() time.Time
+	SetLastModified(t time.Time)
+	SetNotModified(notModified bool)
+}
+
+// setConditions adds the conditional headers of r to request.
+func setConditions(r JSONRequest, request *http.Request) {
+	if cr, ok := r.(ConditionalRequest); ok {
+		if etag := cr.ETag(); etag != "" && request.Header.Get("If-None-Match") == "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+	}
+	if mr, ok := r.(ModifiedSinceRequest); ok {
+		if t := mr.LastModified(); !t.IsZero() && request.Header.Get("If-Modified-Since") == "" {
+			request.Header.Set("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+		}
+	}
+}
+
+// checkConditions passes the validators of resp to r and reports whether resp
+// is a Not Modified response to a conditional request.
+func checkConditions(r JSONRequest, resp *http.Response) bool {
+	notModified := resp.StatusCode == http.StatusNotModified
+	cr, conditional := r.(ConditionalRequest)
+	if conditional {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			cr.SetETag(etag)
+		}
+		cr.SetNotModified(notModified)
+	}
+	mr, modifiedSince := r.(ModifiedSinceRequest)
+	if modifiedSince {
+		if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+			mr.SetLastModified(t)
+		}
+		mr.SetNotModified(notModified)
+	}
+	return notModified && (conditional || modifiedSince)
+}
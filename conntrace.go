@@ -0,0 +1,47 @@
+package jsonrq
+
+import (
+	"net/http"
+	"net/http/httptrace"
+)
+
+// ConnStats counts the connections used by the requests of a Pool created
+// WithConnTrace.
+type ConnStats struct {
+	// New counts requests sent on a freshly dialed connection.
+	New uint64
+	// Reused counts requests sent on a connection used before. WasIdle counts
+	// those of them that took the connection from the idle pool.
+	Reused  uint64
+	WasIdle uint64
+}
+
+// WithConnTrace records whether every request was sent on a new or a reused
+// connection, reported as Stats.Conns. Requests answered from the cache or
+// shared with duplicates don't use a connection.
+func WithConnTrace() Option {
+	return func(w *worker) {
+		w.connTrace = true
+	}
+}
+
+// traceConn adds a trace recording the connection used to request.
+func (w worker) traceConn(request *http.Request) *http.Request {
+	if !w.connTrace || w.counters == nil {
+		return request
+	}
+	c := w.counters
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if !info.Reused {
+				c.connNew.Add(1)
+				return
+			}
+			c.connReused.Add(1)
+			if info.WasIdle {
+				c.connWasIdle.Add(1)
+			}
+		},
+	}
+	return request.WithContext(httptrace.WithClientTrace(request.Context(), trace))
+}
@@ -0,0 +1,58 @@
+package jsonrq
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// ContextRequest can be implemented by a JSONRequest to be performed with its
+// own context, e.g. for a deadline or values used for tracing. The request is
+// aborted once either its context or the context of the Pool is done.
+type ContextRequest interface {
+	JSONRequest
+	Context() context.Context
+}
+
+// ContextBuilder can be implemented by a JSONRequest to create its
+// *http.Request with the context it is performed with, e.g. to read a token
+// stored in the context. RequestWithContext is called instead of Request, also
+// when the request is created again for a retry WithFreshRequests.
+type ContextBuilder interface {
+	JSONRequest
+	RequestWithContext(ctx context.Context) (*http.Request, error)
+}
+
+// buildRequest creates the *http.Request of r with ctx. Without a
+// ContextBuilder, it returns the result of Request and the error of r.
+func buildRequest(ctx context.Context, r JSONRequest) (*http.Request, error) {
+	if cb, ok := r.(ContextBuilder); ok {
+		request, err := cb.RequestWithContext(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating request")
+		}
+		if request == nil {
+			return nil, errors.New("RequestWithContext returned nil")
+		}
+		return request.WithContext(ctx), nil
+	}
+	request := r.Request()
+	if request == nil {
+		return nil, r.Err()
+	}
+	return request.WithContext(ctx), nil
+}
+
+// mergeContext returns a context with the values and deadline of ctx, which is
+// also cancelled once other is done.
+func mergeContext(ctx, other context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(other, func() {
+		cancel(context.Cause(other))
+	})
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
@@ -0,0 +1,74 @@
+package jsonrq
+
+import (
+	"context"
+	"sync"
+)
+
+// CostRequest can be implemented by a JSONRequest to declare how much of the
+// budget set with WithTotalCost it occupies. Requests without a cost, or with
+// a cost below 1, cost 1.
+type CostRequest interface {
+	JSONRequest
+	Cost() int
+}
+
+// WithTotalCost limits the sum of the costs of all requests processed at the
+// same time to budget. Workers wait until enough budget is available before
+// performing a request. A request that costs more than the whole budget is
+// processed once no other request is in flight.
+func WithTotalCost(budget int) Option {
+	return func(w *worker) {
+		w.cost = &costLimit{budget: budget, wake: make(chan struct{})}
+	}
+}
+
+func costOf(r JSONRequest) int {
+	if cr, ok := r.(CostRequest); ok && cr.Cost() > 1 {
+		return cr.Cost()
+	}
+	return 1
+}
+
+// costLimit is a weighted semaphore.
+type costLimit struct {
+	budget int
+
+	mu   sync.Mutex
+	used int
+	// wake is closed and replaced whenever budget is released.
+	wake chan struct{}
+}
+
+// acquire waits until cost can be taken from the budget. The returned function
+// gives it back.
+func (l *costLimit) acquire(ctx context.Context, cost int) (func(), error) {
+	if cost > l.budget {
+		cost = l.budget
+	}
+
+	for {
+		l.mu.Lock()
+		if l.used+cost <= l.budget {
+			l.used += cost
+			l.mu.Unlock()
+			return func() { l.release(cost) }, nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (l *costLimit) release(cost int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= cost
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
@@ -0,0 +1,184 @@
+package jsonrq
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type decoderConfig struct {
+	useNumber       bool
+	disallowUnknown bool
+	strictType      bool
+	requireBody     bool
+	allowEmpty      bool
+}
+
+// WithDecoderConfig configures the json.Decoder used for all responses. With
+// useNumber, numbers decoded into an interface{} are json.Number instead of
+// float64. With disallowUnknown, objects with keys that don't match a field of
+// the destination struct are an error. Requests implementing DecoderRequest
+// are not affected.
+func WithDecoderConfig(useNumber, disallowUnknown bool) Option {
+	return func(w *worker) {
+		w.decoder.useNumber = useNumber
+		w.decoder.disallowUnknown = disallowUnknown
+	}
+}
+
+// WithUseNumber decodes numbers as json.Number instead of float64, so large
+// integers like 64-bit IDs keep their precision. This only affects numbers
+// decoded into an interface{}, e.g. when Data() points to an interface{} or a
+// map[string]interface{}; numbers decoded into typed fields are unaffected. It
+// keeps the rest of the decoder configuration set with WithDecoderConfig.
+func WithUseNumber() Option {
+	return func(w *worker) {
+		w.decoder.useNumber = true
+	}
+}
+
+// WithStrictContentType only decodes responses with a JSON Content-Type, i.e.
+// application/json or a type with a +json suffix. Other responses fail with a
+// *ContentTypeError. Requests implementing DecoderRequest are not affected.
+func WithStrictContentType() Option {
+	return func(w *worker) {
+		w.decoder.strictType = true
+	}
+}
+
+// WithRequireBody fails responses without a body with an *EmptyResponseError,
+// if there is a Data() to decode them into. By default, 204 No Content
+// responses, responses with a Content-Length of 0 and empty responses to
+// OPTIONS and DELETE requests succeed without decoding anything.
+func WithRequireBody() Option {
+	return func(w *worker) {
+		w.decoder.requireBody = true
+	}
+}
+
+// WithAllowEmptyBody lets successful responses with an empty body succeed
+// without decoding anything, for any method. By default, an empty body that
+// isn't declared by the status or the Content-Length fails with an
+// *EmptyResponseError if there is a Data() to decode it into. WithRequireBody
+// takes precedence.
+func WithAllowEmptyBody() Option {
+	return func(w *worker) {
+		w.decoder.allowEmpty = true
+	}
+}
+
+// mayBeEmpty reports whether responses to method often have no body without
+// declaring it, like OPTIONS and DELETE responses.
+func mayBeEmpty(method string) bool {
+	return method == "OPTIONS" || method == "DELETE"
+}
+
+// readerPool holds the *bufio.Readers used to peek into response bodies, so
+// that high request rates don't allocate a new buffer for every response.
+var readerPool = sync.Pool{
+	New: func() interface{} { return bufio.NewReader(nil) },
+}
+
+// peekEmpty reports whether body is empty. The returned reader must be read
+// instead of body, and release must be called once it isn't used anymore.
+func peekEmpty(body io.Reader) (_ io.Reader, empty bool, release func()) {
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(body)
+	_, err := br.Peek(1)
+	return br, err == io.EOF, func() {
+		// Don't keep the body reachable from the pool.
+		br.Reset(nil)
+		readerPool.Put(br)
+	}
+}
+
+// checkContentType returns a *ContentTypeError if header doesn't declare a JSON
+// body.
+func checkContentType(header http.Header) error {
+	ct := header.Get("Content-Type")
+	mt, _, err := mime.ParseMediaType(ct)
+	if err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json")) {
+		return nil
+	}
+	return &ContentTypeError{ContentType: ct}
+}
+
+// StatusDataRequest can be implemented by a JSONRequest whose response shape
+// depends on the status code. DataFor is called with the status of a
+// successful response and returns the value to decode it into, instead of
+// Data(). Like for Data(), a nil value discards the body.
+type StatusDataRequest interface {
+	JSONRequest
+	DataFor(status int) interface{}
+}
+
+// decode parses the body of resp, read from body, into r.
+func (w worker) decode(r JSONRequest, resp *http.Response, body io.Reader) error {
+	if wr, ok := r.(WriterRequest); ok {
+		return writeBody(wr, body)
+	}
+	if mr, ok := r.(MultipartResponseRequest); ok {
+		return w.decodeParts(mr, resp, body)
+	}
+	if dr, ok := r.(DecoderRequest); ok {
+		return newDecodeError(dr.Decode(body), "Error decoding response")
+	}
+
+	sr, stream := r.(StreamRequest)
+	var data interface{}
+	if !stream {
+		switch dr := r.(type) {
+		case StatusDataRequest:
+			data = dr.DataFor(resp.StatusCode)
+		case MultiDecodeRequest:
+			data = dr.SuccessData()
+		default:
+			data = r.Data()
+		}
+		// Without a destination only the status matters.
+		if data == nil {
+			_, err := io.Copy(io.Discard, body)
+			return errors.Wrap(err, "HTTP: Error discarding response")
+		}
+	}
+
+	if !stream {
+		var empty bool
+		var release func()
+		body, empty, release = peekEmpty(body)
+		defer release()
+		if empty {
+			return &EmptyResponseError{Code: resp.StatusCode, Status: resp.Status}
+		}
+	}
+
+	if w.decoder.strictType {
+		if err := checkContentType(resp.Header); err != nil {
+			return err
+		}
+	}
+
+	if stream {
+		return newDecodeError(sr.Stream(w.jsonDecoder(body)), "JSON: Error streaming response")
+	}
+	err := w.decodeJSON(body, data)
+	return newDecodeError(err, "JSON: Error decoding response")
+}
+
+// jsonDecoder returns a json.Decoder for body with the Pool's configuration.
+func (w worker) jsonDecoder(body io.Reader) *json.Decoder {
+	dec := json.NewDecoder(body)
+	if w.decoder.useNumber {
+		dec.UseNumber()
+	}
+	if w.decoder.disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+	return dec
+}
@@ -0,0 +1,75 @@
+package jsonrq
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNilDataDiscardsBody(t *testing.T) {
+	for _, tc := range []struct {
+		status  int
+		wantErr bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusInternalServerError, true},
+	} {
+		client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: tc.status,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader("not json")),
+				Request:    r,
+			}, nil
+		})}
+		rq := FuncRequest(func() (*http.Request, error) {
+			return http.NewRequest("GET", "http://example.com/", nil)
+		}, nil)
+		err := Process(context.Background(), client, rq, WithStrictContentType())
+		if (err != nil) != tc.wantErr {
+			t.Errorf("status %d: got error %v, want error: %v", tc.status, err, tc.wantErr)
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// staticTransport answers every request with body.
+type staticTransport []byte
+
+func (t staticTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(t)),
+		ContentLength: int64(len(t)),
+		Request:       r,
+	}, nil
+}
+
+func benchmarkDecode(b *testing.B, body []byte) {
+	client := &http.Client{Transport: staticTransport(body)}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	for b.Loop() {
+		rq := NewTypedRequest[[]map[string]interface{}]("http://example.com/")
+		if err := Process(context.Background(), client, rq); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeSmall(b *testing.B) {
+	benchmarkDecode(b, []byte(`[{"id":12345,"name":"some name","tags":["a","b","c"]}]`))
+}
+
+func BenchmarkDecodeLarge(b *testing.B) {
+	benchmarkDecode(b, []byte("["+strings.Repeat(`{"id":12345,"name":"some name","tags":["a","b","c"]},`, 1000)+`{}]`))
+}
@@ -0,0 +1,166 @@
+package jsonrq
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// WithDedup makes concurrent GET and HEAD requests for the same URL, or with
+// the same key if they are Keyed, share a single round trip. The response body
+// is buffered and every request decodes its own copy into Data(). If the
+// shared round trip fails, all waiting requests fail with the same error.
+// Requests with credentials, i.e. an Authorization or Cookie header or cookies
+// from the client's jar, are never shared.
+func WithDedup() Option {
+	return func(w *worker) {
+		w.dedup = &flightGroup{m: make(map[string]*flight)}
+	}
+}
+
+// flightGroup tracks the round trips in flight by key.
+type flightGroup struct {
+	mu sync.Mutex
+	m  map[string]*flight
+}
+
+type flight struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// do performs fn once for all concurrent callers with the same key. Every
+// caller receives its own copy of the response. A caller waiting for another
+// one's round trip gives up once ctx is done, and performs fn itself if that
+// round trip was cancelled by the other caller's context.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*http.Response, []byte, error)) (*http.Response, error) {
+	for {
+		g.mu.Lock()
+		f, ok := g.m[key]
+		if !ok {
+			f = &flight{done: make(chan struct{})}
+			g.m[key] = f
+		}
+		g.mu.Unlock()
+
+		if !ok {
+			f.resp, f.body, f.err = fn()
+			g.mu.Lock()
+			delete(g.m, key)
+			g.mu.Unlock()
+			close(f.done)
+		} else {
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, context.Cause(ctx)
+			}
+			if isContextErr(f.err) && ctx.Err() == nil {
+				continue
+			}
+		}
+
+		if f.err != nil {
+			return nil, f.err
+		}
+		return replay(f.resp, f.body), nil
+	}
+}
+
+// isContextErr reports whether err is caused by a cancelled context or an
+// exceeded deadline.
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// replay returns a copy of resp that reads body.
+func replay(resp *http.Response, body []byte) *http.Response {
+	cp := *resp
+	cp.Header = resp.Header.Clone()
+	cp.Body = io.NopCloser(bytes.NewReader(body))
+	return &cp
+}
+
+// send performs request, answering it from the cache or sharing the round trip
+// with identical requests if enabled. cached reports whether the response came
+// from the cache.
+func (w worker) send(r JSONRequest, request *http.Request) (resp *http.Response, cached bool, err error) {
+	key := requestKey(r, request)
+	cacheable := w.cache != nil && request.Method == "GET" && !w.credentialed(request)
+	if cacheable {
+		if resp := w.cache.get(key); resp != nil {
+			return resp, true, nil
+		}
+	}
+
+	resp, err = w.share(key, request)
+	if err != nil || !cacheable {
+		return resp, false, err
+	}
+	resp, err = w.cache.store(key, resp, w.maxBody)
+	return resp, false, err
+}
+
+// Keyed can be implemented by a JSONRequest to define its identity for
+// WithDedup and WithCache, e.g. to include headers or a hash of the body that
+// change the response. Requests are only shared or cached if their method and
+// CacheKey are equal. By default, the key is derived from the URL, the Host
+// and the Range header.
+type Keyed interface {
+	JSONRequest
+	CacheKey() string
+}
+
+// requestKey returns the key identifying request of r for dedup and caching.
+func requestKey(r JSONRequest, request *http.Request) string {
+	if k, ok := r.(Keyed); ok {
+		return request.Method + " " + k.CacheKey()
+	}
+	key := request.Method + " " + request.URL.String()
+	if request.Host != "" && request.Host != request.URL.Host {
+		key += " " + request.Host
+	}
+	if rg := request.Header.Get("Range"); rg != "" {
+		key += " " + rg
+	}
+	return key
+}
+
+// credentialed reports whether request carries credentials, so its response
+// must not be shared with other requests through the cache or deduplication.
+func (w worker) credentialed(request *http.Request) bool {
+	if request.Header.Get("Authorization") != "" || request.Header.Get("Cookie") != "" {
+		return true
+	}
+	return w.client.Jar != nil && len(w.client.Jar.Cookies(request.URL)) > 0
+}
+
+// share performs request, sharing the round trip with identical requests if
+// deduplication is enabled.
+func (w worker) share(key string, request *http.Request) (*http.Response, error) {
+	if w.dedup == nil || (request.Method != "GET" && request.Method != "HEAD") ||
+		w.credentialed(request) {
+		return w.roundTrip(request)
+	}
+
+	return w.dedup.do(request.Context(), key, func() (*http.Response, []byte, error) {
+		resp, err := w.roundTrip(request)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer resp.Body.Close()
+
+		var body io.Reader = resp.Body
+		if w.maxBody > 0 {
+			body = &limitedBody{r: body, n: w.maxBody}
+		}
+		b, err := io.ReadAll(body)
+		return resp, b, err
+	})
+}
@@ -0,0 +1,68 @@
+package jsonrq
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// WithDialer opens the connections of the Pool's transport with d, e.g. to set
+// connection timeouts or a local address.
+func WithDialer(d *net.Dialer) Option {
+	return func(w *worker) {
+		w.dialer = d
+	}
+}
+
+// WithResolver looks up hosts with r, e.g. to use a specific DNS server or a
+// test double. It is combined with the dialer set by WithDialer, if any.
+func WithResolver(r *net.Resolver) Option {
+	return func(w *worker) {
+		w.resolver = r
+	}
+}
+
+// WithTimeouts limits the time to establish a connection to dial, the TLS
+// handshake to tlsHandshake and waiting for the response headers after the
+// request was sent to responseHeader. Unlike the Timeout of the client, none
+// of them limits reading the body. Zero values keep the defaults of
+// http.DefaultTransport. The dial timeout overrides the one of a dialer set
+// with WithDialer.
+func WithTimeouts(dial, tlsHandshake, responseHeader time.Duration) Option {
+	return func(w *worker) {
+		if dial > 0 {
+			w.dialTimeout = dial
+		}
+		withTransport(func(t *http.Transport) {
+			if tlsHandshake > 0 {
+				t.TLSHandshakeTimeout = tlsHandshake
+			}
+			if responseHeader > 0 {
+				t.ResponseHeaderTimeout = responseHeader
+			}
+		})(w)
+	}
+}
+
+// dialSetting returns the transport setting for the configured dialer,
+// resolver and dial timeout, or nil if there are none.
+func (w *worker) dialSetting() func(*http.Transport) {
+	if w.dialer == nil && w.resolver == nil && w.dialTimeout == 0 {
+		return nil
+	}
+
+	// Same defaults as http.DefaultTransport.
+	d := net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if w.dialer != nil {
+		d = *w.dialer
+	}
+	if w.resolver != nil {
+		d.Resolver = w.resolver
+	}
+	if w.dialTimeout > 0 {
+		d.Timeout = w.dialTimeout
+	}
+	return func(t *http.Transport) {
+		t.DialContext = d.DialContext
+	}
+}
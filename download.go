@@ -0,0 +1,123 @@
+package jsonrq
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Download fetches the size bytes of the resource at url into dst, with Range
+// requests for segments of at most segment bytes that are performed in
+// parallel by the workers of p. The first segment is fetched alone: if the
+// server ignores the Range header and sends the whole resource, it is written
+// to dst by that single request. Download fails if any segment fails, after
+// cancelling the others, or if fewer or more bytes than expected were
+// received. Retried segments are written again at the same offset.
+func (p Pool) Download(ctx context.Context, url string, size, segment int64, dst io.WriterAt) error {
+	if size <= 0 {
+		return errors.New("HTTP: Download size must be positive")
+	}
+	if segment <= 0 || segment > size {
+		segment = size
+	}
+
+	first := newRangeSegment(ctx, url, 0, segment, size, dst)
+	if err := p.SubmitAsync(first).Wait(); err != nil {
+		return err
+	}
+	if err := first.check(); err != nil || first.whole {
+		return err
+	}
+
+	var futures []*Future
+	for off := segment; off < size; off += segment {
+		s := newRangeSegment(ctx, url, off, min(segment, size-off), size, dst)
+		futures = append(futures, p.SubmitAsync(s))
+	}
+	var err error
+	for _, f := range futures {
+		ferr := f.Wait()
+		if ferr == nil {
+			ferr = f.Request().(*rangeSegment).check()
+		}
+		if ferr != nil && err == nil {
+			err = ferr
+			for _, f := range futures {
+				f.Cancel()
+			}
+		}
+	}
+	return err
+}
+
+// rangeSegment is a Range request for n bytes at off, written to dst.
+type rangeSegment struct {
+	BasicRequest
+	ctx    context.Context
+	dst    io.WriterAt
+	off, n int64
+	size   int64
+
+	// whole is set if the server sent the whole resource instead of the
+	// range.
+	whole   bool
+	written int64
+}
+
+func newRangeSegment(ctx context.Context, url string, off, n, size int64, dst io.WriterAt) *rangeSegment {
+	s := &rangeSegment{BasicRequest: NewBasicRequest(url), ctx: ctx, dst: dst, off: off, n: n, size: size}
+	s.AddHeader("Range", fmt.Sprintf("bytes=%d-%d", off, off+n-1))
+	return s
+}
+
+func (s *rangeSegment) Data() interface{} { return nil }
+
+func (s *rangeSegment) Done() {}
+
+func (s *rangeSegment) Context() context.Context { return s.ctx }
+
+// Writer returns a writer for the body of the current attempt, which starts
+// at the offset of the segment, or at the start of dst if the server ignored
+// the Range header.
+func (s *rangeSegment) Writer() io.Writer {
+	s.written = 0
+	s.whole = s.StatusCode() == http.StatusOK
+	off := s.off
+	if s.whole {
+		if s.off != 0 {
+			return errWriter{errors.New("HTTP: Server ignored Range header")}
+		}
+		off = 0
+	}
+	return countingWriter{w: io.NewOffsetWriter(s.dst, off), n: &s.written}
+}
+
+// check verifies that the segment was received completely.
+func (s *rangeSegment) check() error {
+	want := s.n
+	if s.whole {
+		want = s.size
+	}
+	if s.written != want {
+		return errors.Errorf("HTTP: Received %d bytes at offset %d, want %d", s.written, s.off, want)
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+type errWriter struct{ err error }
+
+func (e errWriter) Write([]byte) (int, error) { return 0, e.err }
@@ -0,0 +1,79 @@
+package jsonrq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+)
+
+type encoderConfig struct {
+	escapeHTML bool
+	codec      Codec
+}
+
+var defaultEncoderConfig = encoderConfig{escapeHTML: true}
+
+// WithEncoderConfig configures how BasicRequest encodes bodies set with
+// SetBody. Without escapeHTML, the characters <, > and & are sent as they are
+// instead of as \u003c, \u003e and \u0026.
+func WithEncoderConfig(escapeHTML bool) Option {
+	return func(w *worker) {
+		w.encoderConfig().escapeHTML = escapeHTML
+	}
+}
+
+// encoderConfigurer is implemented by BasicRequest and the types embedding it
+// to receive the encoder configuration of the Pool.
+type encoderConfigurer interface {
+	setEncoderConfig(c encoderConfig)
+}
+
+// setEncoderConfig passes the Pool's encoder configuration to r, if any.
+func (w worker) setEncoderConfig(r JSONRequest) {
+	if ec, ok := r.(encoderConfigurer); ok && w.encoder != nil {
+		ec.setEncoderConfig(*w.encoder)
+	}
+}
+
+func (r *BasicRequest) setEncoderConfig(c encoderConfig) {
+	r.encoder = &c
+}
+
+// marshal encodes v according to c.
+func (c encoderConfig) marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if c.codec != nil {
+		if err := c.codec.Encode(&buf, v); err != nil {
+			return nil, err
+		}
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+	}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(c.escapeHTML)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// SetGzipBody sends bodies set with SetBody or SetFormBody gzip compressed,
+// with a Content-Encoding: gzip header. Only enable it for servers that
+// support compressed requests. The body is compressed in memory, so retries
+// and redirects send the same bytes again.
+func (r *BasicRequest) SetGzipBody(gzip bool) {
+	r.gzipBody = gzip
+}
+
+// gzipBody compresses body.
+func gzipBody(body io.Reader) (io.Reader, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(buf.Bytes()), nil
+}
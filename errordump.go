@@ -0,0 +1,55 @@
+package jsonrq
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxErrorDumpSize limits the bytes of a response body written by
+// WithErrorDump.
+var MaxErrorDumpSize = 1 << 20
+
+// WithErrorDump writes the body of every response that failed with a status or
+// decode error to a file in dir, named by the time and a hash of the URL. The
+// file is referenced by the DumpFile field of the *StatusError or
+// *DecodeError. Dumps are best effort: if writing fails, the error is logged
+// and the request fails with its original error. Bodies are cut off after
+// MaxErrorDumpSize bytes; status errors keep at most MaxStatusErrorBody.
+func WithErrorDump(dir string) Option {
+	return func(w *worker) {
+		w.errorDump = dir
+	}
+}
+
+// dumpBody writes body of the response to request to the dump directory and
+// returns the name of the file, or "" if that failed.
+func (w worker) dumpBody(request *http.Request, body []byte) string {
+	sum := sha256.Sum256([]byte(request.URL.String()))
+	name := fmt.Sprintf("%s-%x.body", time.Now().UTC().Format("20060102T150405.000000000"), sum[:8])
+	path := filepath.Join(w.errorDump, name)
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		w.logf("jsonrq: %s %s: error dump failed: %v", request.Method, request.URL, err)
+		return ""
+	}
+	return path
+}
+
+// dumpDecodeError dumps the body kept in dump, if err is a *DecodeError. body
+// is the reader teeing into dump; it is read further until dump is full.
+func (w worker) dumpDecodeError(err error, request *http.Request, dump *capBuffer, body io.Reader) {
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		return
+	}
+	if !dump.full() {
+		io.Copy(io.Discard, io.LimitReader(body, int64(dump.n-len(dump.buf))))
+	}
+	de.DumpFile = w.dumpBody(request, dump.buf)
+}
@@ -0,0 +1,283 @@
+package jsonrq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TransportError is recorded if a request could not be performed, e.g.
+// because of a network error.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return "HTTP: Error performing request: " + e.Err.Error()
+}
+
+func (e *TransportError) Cause() error  { return e.Err }
+func (e *TransportError) Unwrap() error { return e.Err }
+
+// Retryable reports whether the error is caused by the network rather than by
+// the request itself.
+func (e *TransportError) Retryable() bool {
+	return isTransient(e.Err)
+}
+
+// DecodeError is recorded if a response could not be decoded.
+type DecodeError struct {
+	Err error
+	// Body holds the beginning of the response body, if the Pool was
+	// created WithRawBodyOnError.
+	Body []byte
+	// DumpFile is the file the response body was written to, if the Pool
+	// was created WithErrorDump.
+	DumpFile string
+
+	msg string
+}
+
+func newDecodeError(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &DecodeError{Err: err, msg: msg}
+}
+
+func (e *DecodeError) Error() string {
+	msg := e.msg
+	if msg == "" {
+		msg = "Error decoding response"
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Cause() error  { return e.Err }
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// MaxStatusErrorBody limits how much of an error response is kept in a
+// StatusError.
+const MaxStatusErrorBody = 4 << 10
+
+// snippetSize limits how much of StatusError.Body is included in the error
+// message.
+const snippetSize = 512
+
+// StatusError is recorded for responses with a non-2xx status code. The body of
+// such a response is not decoded, but the first MaxStatusErrorBody bytes are
+// kept in Body.
+type StatusError struct {
+	Code   int
+	Status string
+	Body   []byte
+
+	// RetryAfter is the delay requested by the Retry-After header of a 429 or
+	// 503 response, or zero.
+	RetryAfter time.Duration
+	// DumpFile is the file Body was written to, if the Pool was created
+	// WithErrorDump.
+	DumpFile string
+}
+
+func newStatusError(resp *http.Response) *StatusError {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, MaxStatusErrorBody))
+	e := &StatusError{
+		Code:   resp.StatusCode,
+		Status: resp.Status,
+		Body:   b,
+	}
+	if e.Code == http.StatusTooManyRequests || e.Code == http.StatusServiceUnavailable {
+		e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return e
+}
+
+// parseRetryAfter returns the delay of a Retry-After header value, which is
+// either a number of seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func (e *StatusError) Error() string {
+	snippet := bytes.TrimSpace(e.Body)
+	if len(snippet) > snippetSize {
+		snippet = snippet[:snippetSize]
+	}
+	if len(snippet) == 0 {
+		return fmt.Sprintf("HTTP: Unexpected status %s", e.Status)
+	}
+	return fmt.Sprintf("HTTP: Unexpected status %s: %s", e.Status, snippet)
+}
+
+// Retryable reports whether the request might succeed if performed again,
+// which is the case for server errors and 429 Too Many Requests.
+func (e *StatusError) Retryable() bool {
+	return e.Code >= 500 || e.Code == http.StatusTooManyRequests
+}
+
+// CircuitOpenError is recorded for requests that were not sent, because the
+// circuit breaker for their host is open.
+type CircuitOpenError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("HTTP: Circuit open for %s until %s", e.Host, e.Until.Format(time.RFC3339))
+}
+
+// BudgetExceededError is recorded for requests that were not sent or whose
+// response was not read completely, because the byte budget set with
+// WithBudget was used up.
+type BudgetExceededError struct {
+	Budget int64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("HTTP: Budget of %d response bytes exceeded", e.Budget)
+}
+
+// EmptyResponseError is recorded for successful responses without a body, if
+// the request has a Data() to decode it into. It unwraps to io.EOF.
+type EmptyResponseError struct {
+	Code   int
+	Status string
+}
+
+func (e *EmptyResponseError) Error() string {
+	return fmt.Sprintf("HTTP: Status %s but no body was returned", e.Status)
+}
+
+func (e *EmptyResponseError) Unwrap() error { return io.EOF }
+
+// ContentTypeError is recorded for responses that are not declared as JSON, if
+// the Pool was created WithStrictContentType.
+type ContentTypeError struct {
+	ContentType string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("HTTP: Unexpected Content-Type %q", e.ContentType)
+}
+
+// DeadlineError is recorded for requests that failed because their deadline
+// was exceeded before they completed.
+type DeadlineError struct {
+	Err error
+}
+
+func (e *DeadlineError) Error() string {
+	return "Deadline exceeded: " + e.Err.Error()
+}
+
+func (e *DeadlineError) Cause() error  { return e.Err }
+func (e *DeadlineError) Unwrap() error { return e.Err }
+
+// CancelledError is recorded for requests that failed because their context
+// was cancelled, before or while they were performed.
+type CancelledError struct {
+	Err error
+}
+
+func (e *CancelledError) Error() string {
+	return "Cancelled: " + e.Err.Error()
+}
+
+func (e *CancelledError) Cause() error  { return e.Err }
+func (e *CancelledError) Unwrap() error { return e.Err }
+
+// contextError wraps err in a *DeadlineError if it was caused by an exceeded
+// deadline, and in a *CancelledError if it was caused by a cancellation, so
+// that deliberate cancellations can be told apart from other failures.
+func contextError(err error) error {
+	var de *DeadlineError
+	if errors.Is(err, context.DeadlineExceeded) && !errors.As(err, &de) {
+		return &DeadlineError{Err: err}
+	}
+	var ce *CancelledError
+	if errors.Is(err, context.Canceled) && !errors.As(err, &ce) {
+		return &CancelledError{Err: err}
+	}
+	return err
+}
+
+// MultiError combines the errors of several requests, e.g. of a batch. Is and
+// As match the first error in Errors that matches.
+type MultiError struct {
+	errs []error
+}
+
+// newMultiError returns a *MultiError of the non-nil errs, or nil if there are
+// none.
+func newMultiError(errs []error) error {
+	var m MultiError
+	for _, err := range errs {
+		if err != nil {
+			m.errs = append(m.errs, err)
+		}
+	}
+	if len(m.errs) == 0 {
+		return nil
+	}
+	return &m
+}
+
+// Errors returns the combined errors.
+func (m *MultiError) Errors() []error {
+	return m.errs
+}
+
+func (m *MultiError) Error() string {
+	if len(m.errs) == 1 {
+		return m.errs[0].Error()
+	}
+	msgs := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(m.errs), strings.Join(msgs, "; "))
+}
+
+func (m *MultiError) Unwrap() []error { return m.errs }
+
+// labeledError prefixes an error with the label of its request.
+type labeledError struct {
+	label string
+	err   error
+}
+
+func (e *labeledError) Error() string {
+	return "[" + e.label + "] " + e.err.Error()
+}
+
+func (e *labeledError) Cause() error  { return e.err }
+func (e *labeledError) Unwrap() error { return e.err }
+
+// labelError prefixes err with the label of r, if it implements Labeler.
+func labelError(r JSONRequest, err error) error {
+	var le *labeledError
+	if l, ok := r.(Labeler); ok && !errors.As(err, &le) {
+		return &labeledError{label: l.Label(), err: err}
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package jsonrq
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType tells the lifecycle events of a request apart.
+type EventType int
+
+const (
+	// RequestStarted is emitted before the first attempt of a request.
+	RequestStarted EventType = iota
+	// RequestRetried is emitted before every retry, with the attempt that
+	// failed.
+	RequestRetried
+	// RequestCompleted is emitted after the last attempt of a request, with
+	// the final error.
+	RequestCompleted
+)
+
+func (t EventType) String() string {
+	switch t {
+	case RequestStarted:
+		return "started"
+	case RequestRetried:
+		return "retried"
+	case RequestCompleted:
+		return "completed"
+	}
+	return "unknown"
+}
+
+// Event describes a step in the lifecycle of a request. Every page of a
+// PagedRequest emits its own events.
+type Event struct {
+	Type EventType
+	URL  string
+	// Attempt is the number of the latest attempt, starting at 1, or 0 for
+	// RequestStarted.
+	Attempt int
+	// Status is the status code of the latest response, or 0 if there was
+	// none.
+	Status int
+	// Duration is the time since the request was started.
+	Duration time.Duration
+	Err      error
+}
+
+// EventSink receives the lifecycle events of all requests of a Pool.
+type EventSink interface {
+	Event(e Event)
+}
+
+// maxQueuedEvents limits the events waiting for a slow EventSink. Further
+// events are dropped.
+const maxQueuedEvents = 1024
+
+// WithEventSink sends lifecycle events of every request to sink. Events are
+// delivered in order by a separate goroutine, so a slow sink never stalls the
+// workers. If more than 1024 events are waiting to be delivered, new events are
+// dropped.
+func WithEventSink(sink EventSink) Option {
+	return func(w *worker) {
+		w.events = &eventQueue{sink: sink}
+	}
+}
+
+// eventQueue delivers events to its sink, running a goroutine only while
+// events are waiting.
+type eventQueue struct {
+	sink EventSink
+
+	mu      sync.Mutex
+	queue   []Event
+	running bool
+}
+
+func (q *eventQueue) emit(e Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.queue) >= maxQueuedEvents {
+		return
+	}
+	q.queue = append(q.queue, e)
+	if !q.running {
+		q.running = true
+		go q.deliver()
+	}
+}
+
+func (q *eventQueue) deliver() {
+	for {
+		q.mu.Lock()
+		if len(q.queue) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		e := q.queue[0]
+		q.queue = q.queue[1:]
+		q.mu.Unlock()
+
+		q.sink.Event(e)
+	}
+}
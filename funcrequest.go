@@ -0,0 +1,52 @@
+package jsonrq
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FuncRequest returns a JSONRequest that creates its *http.Request with build
+// and decodes the response into data. An error from build fails the request.
+// Done does nothing; check Err once the request was processed.
+func FuncRequest(build func() (*http.Request, error), data interface{}) JSONRequest {
+	return &funcRequest{build: build, data: data}
+}
+
+type funcRequest struct {
+	build func() (*http.Request, error)
+	data  interface{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func (r *funcRequest) Request() *http.Request {
+	request, err := r.build()
+	if err != nil {
+		r.SetErr(errors.Wrap(err, "Error creating FuncRequest"))
+		return nil
+	}
+	return request
+}
+
+func (r *funcRequest) Data() interface{} {
+	return r.data
+}
+
+func (r *funcRequest) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *funcRequest) SetErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.err = err
+	}
+}
+
+func (r *funcRequest) Done() {}
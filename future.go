@@ -0,0 +1,89 @@
+package jsonrq
+
+import (
+	"context"
+)
+
+// Future is a handle to a request scheduled with SubmitAsync.
+type Future struct {
+	rq     JSONRequest
+	done   chan struct{}
+	err    error
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// Request returns the scheduled request.
+func (f *Future) Request() JSONRequest {
+	return f.rq
+}
+
+// Done returns a channel that is closed once the request is done, i.e. after
+// its Done() was called.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the request is done and returns its error.
+func (f *Future) Wait() error {
+	<-f.done
+	if f.err != nil {
+		return f.err
+	}
+	return f.rq.Err()
+}
+
+// Cancel aborts the request. If it wasn't started yet, it is skipped and fails
+// with a *CancelledError. If it is in flight, its context is cancelled. Once
+// the request is done, Cancel has no effect.
+func (f *Future) Cancel() {
+	f.cancel(&CancelledError{Err: context.Canceled})
+}
+
+// futureRequest carries a Future through the queue of a Pool. Workers unwrap
+// it before processing the request.
+type futureRequest struct {
+	JSONRequest
+	f *Future
+}
+
+// handle processes the request of r with the context of w, which is also
+// cancelled by the Future, and completes the Future. A request that was
+// cancelled before it started is completed without performing it.
+func (r *futureRequest) handle(w worker) {
+	if context.Cause(r.f.ctx) != nil {
+		// The cancelled context makes execute skip the request and record
+		// the *CancelledError.
+		w.ctx = r.f.ctx
+		w.handle(r.JSONRequest)
+	} else {
+		var cancel context.CancelFunc
+		w.ctx, cancel = mergeContext(w.ctx, r.f.ctx)
+		w.handle(r.JSONRequest)
+		cancel()
+	}
+	r.f.cancel(nil)
+	close(r.f.done)
+}
+
+// unwrapFuture returns the request carried by r, or r itself.
+func unwrapFuture(r JSONRequest) JSONRequest {
+	if fr, ok := r.(*futureRequest); ok {
+		return fr.JSONRequest
+	}
+	return r
+}
+
+// SubmitAsync schedules rq like Do and returns a Future to wait for it. If the
+// Pool is closed, the Future is done right away and Wait returns
+// ErrPoolClosed.
+func (p Pool) SubmitAsync(rq JSONRequest) *Future {
+	f := &Future{rq: rq, done: make(chan struct{})}
+	f.ctx, f.cancel = context.WithCancelCause(context.Background())
+	if err := p.DoCtx(context.Background(), &futureRequest{JSONRequest: rq, f: f}); err != nil {
+		f.err = err
+		f.cancel(nil)
+		close(f.done)
+	}
+	return f
+}
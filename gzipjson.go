@@ -0,0 +1,31 @@
+package jsonrq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// DecodeGzipJSON decompresses the gzip data and decodes the JSON it contains
+// into v. It is meant for envelopes carrying a compressed JSON document in a
+// base64 encoded field: decoding the envelope into a []byte field already
+// undoes the base64 encoding, so the field can be passed to DecodeGzipJSON
+// afterwards, e.g. in the Validate method of the request.
+func DecodeGzipJSON(data []byte, v interface{}) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "JSON: Error decompressing data")
+	}
+	defer zr.Close()
+	if err := json.NewDecoder(zr).Decode(v); err != nil {
+		return errors.Wrap(err, "JSON: Error decoding decompressed data")
+	}
+	// Reading to the end verifies the gzip checksum.
+	if _, err := io.Copy(io.Discard, zr); err != nil {
+		return errors.Wrap(err, "JSON: Error decompressing data")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package jsonrq
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WorkerStats describes the activity of a single worker of a Pool.
+type WorkerStats struct {
+	// Busy reports whether the worker is processing a request.
+	Busy bool
+	// LastActivity is when the worker last started or finished a request, or
+	// when it was started.
+	LastActivity time.Time
+}
+
+// activity tracks the workers of a Pool.
+type activity struct {
+	mu      sync.Mutex
+	workers map[*workerActivity]struct{}
+}
+
+type workerActivity struct {
+	busy atomic.Bool
+	last atomic.Int64
+}
+
+func (a *activity) register() *workerActivity {
+	wa := new(workerActivity)
+	wa.last.Store(time.Now().UnixNano())
+	a.mu.Lock()
+	a.workers[wa] = struct{}{}
+	a.mu.Unlock()
+	return wa
+}
+
+func (a *activity) unregister(wa *workerActivity) {
+	a.mu.Lock()
+	delete(a.workers, wa)
+	a.mu.Unlock()
+}
+
+func (a *activity) stats() []WorkerStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := make([]WorkerStats, 0, len(a.workers))
+	for wa := range a.workers {
+		stats = append(stats, WorkerStats{
+			Busy:         wa.busy.Load(),
+			LastActivity: time.Unix(0, wa.last.Load()),
+		})
+	}
+	return stats
+}
+
+func (wa *workerActivity) set(busy bool) {
+	wa.last.Store(time.Now().UnixNano())
+	wa.busy.Store(busy)
+}
+
+// Healthy reports whether no worker has been busy with the same request for
+// longer than stall, e.g. because of a hung connection. Use Stats for the
+// activity of the individual workers.
+func (p Pool) Healthy(stall time.Duration) bool {
+	for _, ws := range p.w.activity.stats() {
+		if ws.Busy && time.Since(ws.LastActivity) > stall {
+			return false
+		}
+	}
+	return true
+}
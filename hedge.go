@@ -0,0 +1,101 @@
+package jsonrq
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WithHedge sends a second copy of a request with an idempotent method if no
+// response arrived within after, and uses whichever response arrives first. The
+// other copy is cancelled. Each attempt of a retried request is hedged on its
+// own.
+func WithHedge(after time.Duration) Option {
+	return func(w *worker) {
+		w.hedge = after
+	}
+}
+
+// roundTrip sends request with the client, hedging it if enabled.
+func (w worker) roundTrip(request *http.Request) (*http.Response, error) {
+	if w.hedge <= 0 || !idempotentMethod(request.Method) {
+		return w.client.Do(request)
+	}
+	return w.hedged(request)
+}
+
+type hedgeResult struct {
+	resp *http.Response
+	err  error
+	i    int
+}
+
+// hedged sends request and, if it didn't complete after w.hedge, a copy of it.
+// It returns the first successful round trip, or the last error if all failed.
+func (w worker) hedged(request *http.Request) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	send := func(request *http.Request) {
+		ctx, cancel := context.WithCancel(request.Context())
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := w.client.Do(request.WithContext(ctx))
+			results <- hedgeResult{resp: resp, err: err, i: i}
+		}()
+	}
+
+	send(request)
+	pending := 1
+	t := time.NewTimer(w.hedge)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if next, err := rewind(request); err == nil {
+				send(next)
+				pending++
+			}
+		case res := <-results:
+			pending--
+			if res.err != nil {
+				cancels[res.i]()
+				if pending > 0 {
+					continue
+				}
+				return nil, res.err
+			}
+
+			for i, cancel := range cancels {
+				if i != res.i {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go discardHedge(results)
+			}
+			res.resp.Body = &cancelBody{ReadCloser: res.resp.Body, cancel: cancels[res.i]}
+			return res.resp, nil
+		}
+	}
+}
+
+// discardHedge closes the response of the cancelled round trip, if any.
+func discardHedge(results <-chan hedgeResult) {
+	if res := <-results; res.resp != nil {
+		res.resp.Body.Close()
+	}
+}
+
+// cancelBody cancels the context of its round trip once closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
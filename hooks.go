@@ -0,0 +1,33 @@
+package jsonrq
+
+import (
+	"net/http"
+)
+
+// WithRequestHook calls hook with every *http.Request right before it is sent,
+// e.g. to sign it. It is called again for every retry. Multiple hooks are
+// called in the order they were added.
+func WithRequestHook(hook func(*http.Request)) Option {
+	return func(w *worker) {
+		w.requestHooks = append(w.requestHooks, hook)
+	}
+}
+
+// WithResponseHook calls hook with every *http.Response before its status is
+// checked and its body is decoded. Multiple hooks are called in the order they
+// were added.
+func WithResponseHook(hook func(*http.Response)) Option {
+	return func(w *worker) {
+		w.responseHooks = append(w.responseHooks, hook)
+	}
+}
+
+// WithUserAgent sets the User-Agent header to ua on every request that doesn't
+// set its own.
+func WithUserAgent(ua string) Option {
+	return WithRequestHook(func(request *http.Request) {
+		if request.Header.Get("User-Agent") == "" {
+			request.Header.Set("User-Agent", ua)
+		}
+	})
+}
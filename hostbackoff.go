@@ -0,0 +1,75 @@
+package jsonrq
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Delays of the adaptive host backoff.
+const (
+	hostBackoffBase = 100 * time.Millisecond
+	hostBackoffMax  = 10 * time.Second
+)
+
+// WithAdaptiveHostBackoff delays requests to a host after connection level
+// failures, e.g. refused connections. The delay starts at 100ms, doubles with
+// every further failure up to 10s and is randomized, so that workers don't
+// retry in lockstep. Any response from the host resets it. Unlike
+// WithCircuitBreaker, requests are only delayed, never failed, and other hosts
+// are not affected.
+func WithAdaptiveHostBackoff() Option {
+	return func(w *worker) {
+		w.hostBackoff = &hostBackoff{hosts: make(map[string]*hostDelay)}
+	}
+}
+
+type hostBackoff struct {
+	mu    sync.Mutex
+	hosts map[string]*hostDelay
+}
+
+type hostDelay struct {
+	failures int
+	until    time.Time
+}
+
+// wait blocks until requests to host are allowed again.
+func (b *hostBackoff) wait(ctx context.Context, host string) error {
+	b.mu.Lock()
+	var d time.Duration
+	if h := b.hosts[host]; h != nil {
+		d = time.Until(h.until)
+	}
+	b.mu.Unlock()
+	return sleep(ctx, d)
+}
+
+// done records the outcome of a request to host.
+func (b *hostBackoff) done(host string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var te *TransportError
+	if !errors.As(err, &te) || !isTransient(te.Err) {
+		delete(b.hosts, host)
+		return
+	}
+
+	h := b.hosts[host]
+	if h == nil {
+		h = new(hostDelay)
+		b.hosts[host] = h
+	}
+	h.failures++
+	d := hostBackoffMax
+	if h.failures <= 7 {
+		d = min(hostBackoffBase<<(h.failures-1), hostBackoffMax)
+	}
+	// Wait between half and the full delay.
+	d -= time.Duration(rand.Int63n(int64(d/2) + 1))
+	h.until = time.Now().Add(d)
+}
@@ -0,0 +1,61 @@
+package jsonrq
+
+import (
+	"context"
+	"sync"
+)
+
+// WithPerHostLimit allows at most n concurrent requests per host across all
+// workers of the Pool. Workers wait for a free slot before sending a request,
+// requests to other hosts are not affected. An n of zero or less means no
+// limit.
+func WithPerHostLimit(n int) Option {
+	return func(w *worker) {
+		if n <= 0 {
+			w.hostLimit = nil
+			return
+		}
+		w.hostLimit = &hostLimit{n: n, sems: make(map[string]chan struct{})}
+	}
+}
+
+type hostLimit struct {
+	n int
+
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+// acquire waits for a free slot for host. The returned function releases the
+// slot.
+func (l *hostLimit) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	sem, ok := l.sems[host]
+	if !ok {
+		sem = make(chan struct{}, l.n)
+		l.sems[host] = sem
+	}
+	l.mu.Unlock()
+
+	return acquireSem(ctx, sem)
+}
+
+// WithSharedLimiter makes every attempt hold a slot of sem while it is
+// performed. Sharing sem between Pools caps the number of concurrent requests
+// of all of them combined to the capacity of sem.
+func WithSharedLimiter(sem chan struct{}) Option {
+	return func(w *worker) {
+		w.shared = sem
+	}
+}
+
+// acquireSem waits for a free slot of sem. The returned function releases the
+// slot.
+func acquireSem(ctx context.Context, sem chan struct{}) (func(), error) {
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,40 @@
+package jsonrq
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// IdempotencyKeyHeader is the header carrying the idempotency key of a request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKeys sets a random UUID as Idempotency-Key header on every
+// request with a non-idempotent method, unless the request already carries a
+// key. The key is the same for all retries of a request. Requests with an
+// Idempotency-Key header are retried like idempotent requests.
+func WithIdempotencyKeys() Option {
+	return func(w *worker) {
+		w.idempotencyKeys = true
+	}
+}
+
+// setIdempotencyKey adds a generated key to request if needed.
+func (w worker) setIdempotencyKey(request *http.Request) {
+	if !w.idempotencyKeys || idempotentMethod(request.Method) ||
+		request.Header.Get(IdempotencyKeyHeader) != "" {
+		return
+	}
+	request.Header.Set(IdempotencyKeyHeader, newUUID())
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
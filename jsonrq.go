@@ -1,14 +1,27 @@
 package jsonrq
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 )
 
-// JSONRequest is the Interface the Poolworkers work on.
+// JSONRequest is the Interface the Poolworkers work on. If Data returns nil,
+// the response body is read and discarded, so only the status matters.
 type JSONRequest interface {
 	Request() *http.Request
 	Data() interface{}
@@ -17,24 +30,370 @@ type JSONRequest interface {
 	Done()
 }
 
+// CallbackRequest can be implemented by a JSONRequest to be notified with the
+// final error, right before Done() is called.
+type CallbackRequest interface {
+	JSONRequest
+	OnComplete(err error)
+}
+
+// TimeoutRequest can be implemented by a JSONRequest to limit the time it may
+// take, including all retries. A zero Timeout means no limit.
+type TimeoutRequest interface {
+	JSONRequest
+	Timeout() time.Duration
+}
+
+// DeadlineRequest can be implemented by a JSONRequest to finish by an absolute
+// deadline, including all retries, e.g. one shared by a larger operation. A
+// request whose deadline already passed fails with a *DeadlineError without
+// being sent.
+type DeadlineRequest interface {
+	JSONRequest
+	Deadline() (deadline time.Time, ok bool)
+}
+
+// DecoderRequest can be implemented by a JSONRequest to parse the response body
+// itself instead of having it decoded as JSON into Data().
+type DecoderRequest interface {
+	JSONRequest
+	Decode(r io.Reader) error
+}
+
+// HeaderReceiver can be implemented by a JSONRequest to receive the headers of
+// every response, including unsuccessful ones.
+type HeaderReceiver interface {
+	JSONRequest
+	SetResponseHeaders(header http.Header)
+}
+
+// Labeler can be implemented by a JSONRequest to prefix its errors with a
+// label, e.g. to correlate them in logs.
+type Labeler interface {
+	JSONRequest
+	Label() string
+}
+
+// TimingReceiver can be implemented by a JSONRequest to receive the time it
+// took to process it, including all retries.
+type TimingReceiver interface {
+	JSONRequest
+	SetTiming(start, end time.Time)
+}
+
+// ResponseMetaReceiver can be implemented by a JSONRequest to receive the
+// status code, protocol and Content-Length (-1 if unknown) of every response,
+// including unsuccessful ones.
+type ResponseMetaReceiver interface {
+	JSONRequest
+	SetResponseMeta(statusCode int, proto string, contentLength int64)
+}
+
+// FinalURLReceiver can be implemented by a JSONRequest to receive the URL of
+// every response after following redirects.
+type FinalURLReceiver interface {
+	JSONRequest
+	SetFinalURL(u *url.URL)
+}
+
 // BasicRequest is a type to simplify satisfying the JSONRequest by embedding
 // common functionality.
 type BasicRequest struct {
+	mu  sync.Mutex
 	err error
-	url string
+
+	url        string
+	method     string
+	body       interface{}
+	reader     io.Reader
+	readerType string
+	form       url.Values
+	parts      []part
+	query      url.Values
+	auth       string
+	host       string
+
+	encoder   *encoderConfig
+	gzipBody  bool
+	spoolBody bool
+	spooled   *spooledBody
+
+	etag         string
+	lastModified time.Time
+	notModified  bool
+
+	start, end time.Time
+	finalURL   *url.URL
+	fromCache  bool
+	requestID  string
+
+	statusCode    int
+	proto         string
+	contentLength int64
+
+	// Header is added to every *http.Request created by Request.
+	Header http.Header
 }
 
 func NewBasicRequest(url string) BasicRequest {
 	return BasicRequest{url: url}
 }
 
-// Err returns the latest error
+// NewBasicRequestWithMethod creates a BasicRequest that uses the given HTTP
+// method instead of GET.
+func NewBasicRequestWithMethod(method, url string) BasicRequest {
+	return BasicRequest{url: url, method: method}
+}
+
+// SetMethod sets the HTTP method. An empty method defaults to GET.
+func (r *BasicRequest) SetMethod(method string) {
+	r.method = method
+}
+
+// SetBody sets a payload that is sent JSON encoded as the request body. The
+// encoded body is kept in memory, so it is sent again on retries and when
+// following 307 and 308 redirects.
+func (r *BasicRequest) SetBody(body interface{}) {
+	r.clearBody()
+	r.body = body
+}
+
+// SetFormBody sets values that are sent form-urlencoded as the request body.
+// It replaces a body set with SetBody. Like with SetBody, the body is replayed
+// on retries and redirects.
+func (r *BasicRequest) SetFormBody(values url.Values) {
+	r.clearBody()
+	r.form = values
+}
+
+// SetBodyReader sets a payload that is sent as is as the request body with the
+// given Content-Type. It replaces a body set with SetBody or SetFormBody. An
+// io.Seeker body is not closed after sending it.
+//
+// Only bodies that can be read again are retried: a *bytes.Buffer,
+// *bytes.Reader or *strings.Reader, or an io.Seeker such as an *os.File, which
+// is rewound to its current offset for every attempt. Other readers are sent
+// once, and a failed attempt is not retried.
+func (r *BasicRequest) SetBodyReader(body io.Reader, contentType string) {
+	r.clearBody()
+	r.reader = body
+	r.readerType = contentType
+}
+
+func (r *BasicRequest) clearBody() {
+	r.reader = nil
+	r.readerType = ""
+	r.body = nil
+	r.form = nil
+	r.parts = nil
+}
+
+// encodeBody returns the request body, its Content-Type and whether it is gzip
+// compressed.
+func (r *BasicRequest) encodeBody() (io.Reader, string, bool, error) {
+	body, contentType, err := r.marshalBody()
+	if err != nil {
+		return nil, "", false, errors.Wrap(err, "Error encoding BasicRequest body")
+	}
+	if !r.gzipBody || (r.body == nil && r.form == nil) {
+		return body, contentType, false, nil
+	}
+	if body, err = gzipBody(body); err != nil {
+		return nil, "", false, errors.Wrap(err, "Error compressing BasicRequest body")
+	}
+	return body, contentType, true, nil
+}
+
+// marshalBody returns the request body and its Content-Type.
+func (r *BasicRequest) marshalBody() (io.Reader, string, error) {
+	switch {
+	case r.reader != nil:
+		return r.reader, r.readerType, nil
+	case r.parts != nil:
+		body, contentType := multipartBody(r.parts)
+		return body, contentType, nil
+	case r.form != nil:
+		return strings.NewReader(r.form.Encode()), "application/x-www-form-urlencoded", nil
+	case r.body != nil:
+		enc := defaultEncoderConfig
+		if r.encoder != nil {
+			enc = *r.encoder
+		}
+		b, err := enc.marshal(r.body)
+		if err != nil {
+			return nil, "", err
+		}
+		return bytes.NewReader(b), "application/json", nil
+	}
+	return nil, "", nil
+}
+
+// seekable makes an io.Seeker body replayable, which http.NewRequest only does
+// for in-memory bodies. The body is wrapped so that the transport doesn't close
+// it after the first attempt.
+func seekable(body io.Reader) (io.Reader, func() (io.ReadCloser, error)) {
+	switch body.(type) {
+	case nil, *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		return body, nil
+	}
+	s, ok := body.(io.ReadSeeker)
+	if !ok {
+		return body, nil
+	}
+	off, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return body, nil
+	}
+	return io.NopCloser(s), func() (io.ReadCloser, error) {
+		if _, err := s.Seek(off, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(s), nil
+	}
+}
+
+// SetQuery replaces the query parameters added to the URL. They are merged
+// with the parameters already present in the URL.
+func (r *BasicRequest) SetQuery(query url.Values) {
+	r.query = query
+}
+
+// AddQuery adds the key, value pair to the query parameters.
+func (r *BasicRequest) AddQuery(key, value string) {
+	if r.query == nil {
+		r.query = make(url.Values)
+	}
+	r.query.Add(key, value)
+}
+
+// AddHeader adds the key, value pair to the request headers. Values of the same
+// key are accumulated.
+func (r *BasicRequest) AddHeader(key, value string) {
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Add(key, value)
+}
+
+// SetHost sends the request with host as Host header instead of the host of
+// the URL, e.g. to address a virtual host behind a load balancer or by IP. In
+// Go, the Host header is set by http.Request.Host; a Host entry in Header is
+// ignored when the request is sent.
+func (r *BasicRequest) SetHost(host string) {
+	r.host = host
+}
+
+// SetIdempotencyKey sets the Idempotency-Key header, which allows servers
+// supporting it to detect retries of the request.
+func (r *BasicRequest) SetIdempotencyKey(key string) {
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	r.Header.Set(IdempotencyKeyHeader, key)
+}
+
+// SetBasicAuth authenticates the request with HTTP Basic Authentication.
+func (r *BasicRequest) SetBasicAuth(username, password string) {
+	cred := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	r.auth = "Basic " + cred
+}
+
+// SetBearerToken authenticates the request with a bearer token.
+func (r *BasicRequest) SetBearerToken(token string) {
+	r.auth = "Bearer " + token
+}
+
+// ETag returns the entity tag sent as If-None-Match. It is updated from every
+// response.
+func (r *BasicRequest) ETag() string {
+	return r.etag
+}
+
+// SetETag sets the entity tag sent as If-None-Match.
+func (r *BasicRequest) SetETag(etag string) {
+	r.etag = etag
+}
+
+// LastModified returns the time sent as If-Modified-Since. It is updated from
+// every response with a Last-Modified header.
+func (r *BasicRequest) LastModified() time.Time {
+	return r.lastModified
+}
+
+// SetLastModified sets the time sent as If-Modified-Since.
+func (r *BasicRequest) SetLastModified(t time.Time) {
+	r.lastModified = t
+}
+
+// NotModified reports whether the server answered with 304 Not Modified.
+func (r *BasicRequest) NotModified() bool {
+	return r.notModified
+}
+
+// SetNotModified is called by the workers to record a 304 Not Modified
+// response.
+func (r *BasicRequest) SetNotModified(notModified bool) {
+	r.notModified = notModified
+}
+
+// SetTiming is called by the workers with the time it took to process the
+// request.
+func (r *BasicRequest) SetTiming(start, end time.Time) {
+	r.start, r.end = start, end
+}
+
+// Elapsed returns how long it took to process the request.
+func (r *BasicRequest) Elapsed() time.Duration {
+	return r.end.Sub(r.start)
+}
+
+// SetResponseMeta is called by the workers with the status line and
+// Content-Length of every response.
+func (r *BasicRequest) SetResponseMeta(statusCode int, proto string, contentLength int64) {
+	r.statusCode, r.proto, r.contentLength = statusCode, proto, contentLength
+}
+
+// StatusCode returns the status code of the last response, or 0 if there was
+// none.
+func (r *BasicRequest) StatusCode() int {
+	return r.statusCode
+}
+
+// Proto returns the protocol of the last response, e.g. "HTTP/2.0".
+func (r *BasicRequest) Proto() string {
+	return r.proto
+}
+
+// ContentLength returns the Content-Length of the last response, or -1 if it
+// was unknown.
+func (r *BasicRequest) ContentLength() int64 {
+	return r.contentLength
+}
+
+// SetFinalURL is called by the workers with the URL of the response after
+// following redirects.
+func (r *BasicRequest) SetFinalURL(u *url.URL) {
+	r.finalURL = u
+}
+
+// FinalURL returns the URL of the last response after following redirects, or
+// nil if there was no response.
+func (r *BasicRequest) FinalURL() *url.URL {
+	return r.finalURL
+}
+
+// Err returns the latest error. It is safe for concurrent use with SetErr.
 func (r *BasicRequest) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.err
 }
 
-// SetErr overwrites the last error with err. Never clears an error.
+// SetErr overwrites the last error with err. Never clears an error. It is safe
+// for concurrent use.
 func (r *BasicRequest) SetErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	// Don't discard Errors
 	if r.err == nil || err != nil {
 		r.err = err
@@ -43,80 +402,1063 @@ func (r *BasicRequest) SetErr(err error) {
 
 // Request prepares an *http.Request for the workers to fetch and decode
 func (r *BasicRequest) Request() *http.Request {
-	request, err := http.NewRequest("GET", r.url, nil)
+	target := r.url
+	if len(r.query) > 0 {
+		u, err := url.Parse(r.url)
+		if err != nil {
+			r.SetErr(errors.Wrap(err, "Error parsing BasicRequest URL"))
+			return nil
+		}
+		q := u.Query()
+		for k, vs := range r.query {
+			for _, v := range vs {
+				q.Add(k, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+		target = u.String()
+	}
+	return r.newRequest(target)
+}
+
+// newRequest creates an *http.Request for target with the method, body and
+// headers of r.
+func (r *BasicRequest) newRequest(target string) *http.Request {
+	method := r.method
+	if method == "" {
+		method = "GET"
+	}
+
+	if r.spoolBody && r.spooled == nil {
+		if err := r.spool(); err != nil {
+			r.SetErr(errors.Wrap(err, "Error spooling BasicRequest body"))
+			return nil
+		}
+	}
+	var body io.Reader
+	var getBody func() (io.ReadCloser, error)
+	var contentType string
+	var gzipped bool
+	var err error
+	if r.spoolBody {
+		if body, err = r.spooled.open(); err != nil {
+			r.SetErr(errors.Wrap(err, "Error opening spooled BasicRequest body"))
+			return nil
+		}
+		getBody, contentType, gzipped = r.spooled.reopen, r.spooled.contentType, r.spooled.gzipped
+	} else {
+		if body, contentType, gzipped, err = r.encodeBody(); err != nil {
+			r.SetErr(err)
+			return nil
+		}
+		body, getBody = seekable(body)
+	}
+	request, err := http.NewRequest(method, target, body)
 	r.SetErr(errors.Wrap(err, "Error creating BasicRequest"))
+	if request == nil {
+		return nil
+	}
+	if getBody != nil {
+		request.GetBody = getBody
+	}
+	if r.spoolBody {
+		request.ContentLength = r.spooled.size
+	}
+
+	for k, vs := range r.Header {
+		for _, v := range vs {
+			request.Header.Add(k, v)
+		}
+	}
+	if r.auth != "" {
+		request.Header.Set("Authorization", r.auth)
+	}
+	if contentType != "" && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	if gzipped {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	if r.host != "" {
+		request.Host = r.host
+	}
 	return request
 }
 
 // Worker drains the in channel and processes all JSONRequests. If in is
 // closed, it calls Done() on the supplied *sync.WorkGroup
 func Worker(in <-chan JSONRequest, wg *sync.WaitGroup) {
-	for r := range in {
-		func(r JSONRequest) {
-			resp, err := http.DefaultClient.Do(r.Request())
-			if err != nil {
-				r.SetErr(errors.Wrap(err, "HTTP: Error performing request"))
+	WorkerContext(context.Background(), in, wg)
+}
+
+// WorkerContext works like Worker, but performs all requests with ctx. If ctx
+// is cancelled, in-flight requests are aborted and all remaining requests fail
+// with a *CancelledError wrapping ctx.Err() without being performed. The worker
+// keeps draining in until it is closed, so producers never block on a cancelled
+// worker.
+func WorkerContext(ctx context.Context, in <-chan JSONRequest, wg *sync.WaitGroup) {
+	w := worker{ctx: ctx, client: http.DefaultClient}
+	w.run(in, nil, wg)
+}
+
+// WorkerStop works like Worker, but also exits once stop is closed or a value
+// is received from it, after finishing its current request and without in
+// being closed. wg.Done is called in either case. Sending a value stops only
+// one of the workers sharing stop, closing it stops all of them.
+func WorkerStop(in <-chan JSONRequest, stop <-chan struct{}, wg *sync.WaitGroup) {
+	w := worker{ctx: context.Background(), client: http.DefaultClient}
+	w.run(in, stop, wg)
+}
+
+// newWorker applies opts to w.
+func newWorker(w worker, opts []Option) worker {
+	for _, opt := range opts {
+		opt(&w)
+	}
+	if w.propagatePanics {
+		w.ctx, w.cancelPool = context.WithCancelCause(w.ctx)
+	}
+	w.configureClient()
+	return w
+}
+
+// worker holds the settings shared by all workers of a Pool.
+type worker struct {
+	ctx    context.Context
+	client *http.Client
+
+	propagatePanics bool
+	// cancelPool cancels ctx once a request panicked.
+	cancelPool context.CancelCauseFunc
+
+	transport      []func(*http.Transport)
+	clientSettings []func(*http.Client)
+	dialer         *net.Dialer
+	resolver       *net.Resolver
+	dialTimeout    time.Duration
+
+	retries         int
+	retryAny        bool
+	retryTruncated  bool
+	retryBudget     *retryBudget
+	freshRequests   bool
+	idempotencyKeys bool
+	requestID       func() string
+	defaultQuery    url.Values
+	rewriteURL      func(*url.URL) *url.URL
+	backoff         backoff
+	strategy        BackoffStrategy
+
+	limiter     *rate.Limiter
+	jitter      time.Duration
+	hostLimit   *hostLimit
+	shared      chan struct{}
+	breaker     *breaker
+	hostBackoff *hostBackoff
+	cost        *costLimit
+	budget      *budget
+	priority    bool
+
+	maxBody       int64
+	bodyTimeout   time.Duration
+	decompress    bool
+	decompressors map[string]func(io.Reader) io.Reader
+	decoder       decoderConfig
+	encoder       *encoderConfig
+	codec         Codec
+	rawBody       int
+	errorDump     string
+	bufferBody    bool
+
+	dedup *flightGroup
+	cache *cache
+	hedge time.Duration
+
+	middleware    []Middleware
+	requestHooks  []func(*http.Request)
+	responseHooks []func(*http.Response)
+
+	metrics   Metrics
+	tracer    Tracer
+	connTrace bool
+	logger    Logger
+	events    *eventQueue
+
+	// quit tells a single worker to exit.
+	quit chan struct{}
+
+	// completed is called after a request is done.
+	completed func(JSONRequest)
+
+	results  *results
+	counters *counters
+	pending  *pending
+	activity *activity
+}
+
+// results emits completed requests once enabled. ch is replaced when a
+// stopped Pool is restarted.
+type results struct {
+	on atomic.Bool
+	mu sync.Mutex
+	ch chan JSONRequest
+}
+
+func (r *results) channel() chan JSONRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ch
+}
+
+// Option configures a Pool.
+type Option func(*worker)
+
+// run processes requests from in until in is closed or stop receives.
+func (w worker) run(in <-chan JSONRequest, stop <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	var wa *workerActivity
+	if w.activity != nil {
+		wa = w.activity.register()
+		defer w.activity.unregister(wa)
+	}
+	for {
+		// Don't pick up another request once told to stop.
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		select {
+		case <-stop:
+			return
+		case r, ok := <-in:
+			if !ok {
 				return
 			}
+			if w.counters != nil {
+				w.counters.queued.Add(-1)
+			}
+			if wa != nil {
+				wa.set(true)
+			}
+			w.handle(r)
+			if wa != nil {
+				wa.set(false)
+			}
+		}
+	}
+}
 
-			defer func() {
-				r.SetErr(resp.Body.Close())
-			}()
+// handle executes r and completes it.
+func (w worker) handle(r JSONRequest) {
+	if fr, ok := r.(*futureRequest); ok {
+		fr.handle(w)
+		return
+	}
 
-			err = json.NewDecoder(resp.Body).Decode(r.Data())
-			if err != nil {
-				r.SetErr(errors.Wrap(err, "JSON: Error decoding response"))
-				return
+	w.execute(r)
+
+	if cb, ok := r.(CallbackRequest); ok {
+		cb.OnComplete(r.Err())
+	}
+	r.Done()
+	if w.completed != nil {
+		w.completed(r)
+	}
+	if w.pending != nil {
+		w.pending.add(-1)
+	}
+	if w.results != nil && w.results.on.Load() {
+		w.results.channel() <- r
+	}
+}
+
+// execute processes r and records its error. Once the context is done, r fails
+// with the context's error without being performed.
+func (w worker) execute(r JSONRequest) error {
+	if w.counters != nil {
+		w.counters.start()
+	}
+	err := w.ctx.Err()
+	if err != nil {
+		err = context.Cause(w.ctx)
+	} else {
+		err = w.serve(r)
+	}
+	if err != nil {
+		r.SetErr(labelError(r, contextError(err)))
+	}
+	if w.counters != nil {
+		w.counters.done(r.Err())
+	}
+	return r.Err()
+}
+
+// process performs r and returns the final error, retrying transient failures
+// as configured. A panic while processing r is returned as error.
+func (w worker) process(r JSONRequest) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = w.recovered(p)
+		}
+	}()
+
+	defer removeSpooled(r)
+
+	if tr, ok := r.(TimingReceiver); ok {
+		defer func(start time.Time) {
+			tr.SetTiming(start, time.Now())
+		}(time.Now())
+	}
+
+	ctx := w.ctx
+	if cr, ok := r.(ContextRequest); ok && cr.Context() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = mergeContext(cr.Context(), ctx)
+		defer cancel()
+	}
+	if tr, ok := r.(TimeoutRequest); ok && tr.Timeout() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tr.Timeout())
+		defer cancel()
+	}
+	if dr, ok := r.(DeadlineRequest); ok {
+		if deadline, ok := dr.Deadline(); ok {
+			if !time.Now().Before(deadline) {
+				return context.DeadlineExceeded
 			}
-		}(r)
-		r.Done()
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+			defer cancel()
+		}
+	}
+
+	w.setEncoderConfig(r)
+	request, err := buildRequest(ctx, r)
+	if request == nil {
+		return err
 	}
-	wg.Done()
+	setConditions(r, request)
+	w.setIdempotencyKey(request)
+	w.setRequestID(r, request)
+
+	if w.cost != nil {
+		release, err := w.cost.acquire(ctx, costOf(r))
+		if err != nil {
+			return errors.Wrap(err, "Error waiting for cost budget")
+		}
+		defer release()
+	}
+
+	// Only the first page is created by Request.
+	fresh := w.freshRequests
+	for request != nil {
+		w.logf("jsonrq: %s %s: started", request.Method, request.URL)
+		if err := w.perform(r, request, fresh); err != nil {
+			w.logf("jsonrq: %s %s: failed: %v", request.Method, request.URL, err)
+			return err
+		}
+		w.logf("jsonrq: %s %s: done", request.Method, request.URL)
+
+		request, fresh = nil, false
+		if pr, ok := r.(PagedRequest); ok {
+			if next := pr.NextPage(); next != nil {
+				request = next.WithContext(ctx)
+			}
+		}
+	}
+
+	if v, ok := r.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return errors.Wrap(err, "Validation failed")
+		}
+	}
+	return nil
+}
+
+// perform sends request until it succeeds or the retries are exhausted and
+// returns the final error. With fresh, retries are created by r.Request()
+// instead of replaying request.
+func (w worker) perform(r JSONRequest, request *http.Request, fresh bool) (err error) {
+	ctx := request.Context()
+	retries := w.retries
+	if rr, ok := r.(RetryableRequest); ok {
+		retries = rr.MaxRetries()
+	}
+	if !w.retryable(r, request) {
+		retries = 0
+	}
+
+	var attempts, status int
+	retried := func(error) {}
+	if w.events != nil {
+		start := time.Now()
+		event := func(typ EventType, err error) {
+			w.events.emit(Event{
+				Type:     typ,
+				URL:      request.URL.String(),
+				Attempt:  attempts,
+				Status:   status,
+				Duration: time.Since(start),
+				Err:      err,
+			})
+		}
+		event(RequestStarted, nil)
+		defer func() {
+			event(RequestCompleted, err)
+		}()
+		retried = func(err error) {
+			event(RequestRetried, err)
+		}
+	}
+
+	if w.retryBudget != nil {
+		w.retryBudget.deposit()
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := w.attempt(r, request)
+		attempts, status = attempt+1, 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		if err == nil || attempt >= retries || !w.shouldRetry(r, resp, err, attempt+1) {
+			return err
+		}
+		if w.retryBudget != nil && !w.retryBudget.withdraw() {
+			w.logf("jsonrq: %s %s: retry budget exhausted: %v", request.Method, request.URL, err)
+			return err
+		}
+
+		retried(err)
+		w.logf("jsonrq: %s %s: retrying after attempt %d: %v", request.Method, request.URL, attempt+1, err)
+		if ctx.Err() != nil {
+			return err
+		}
+		delay := w.retryDelay(attempt, err)
+		if ro, ok := r.(RetryObserver); ok {
+			ro.OnRetry(attempt+1, err, delay)
+		}
+		if sleep(ctx, delay) != nil {
+			return err
+		}
+
+		var next *http.Request
+		var rerr error
+		if fresh {
+			next, rerr = rebuild(r, request)
+		} else {
+			next, rerr = rewind(request)
+		}
+		if rerr != nil {
+			return err
+		}
+		request = next
+	}
+}
+
+// attempt performs a single round trip of request and decodes the response
+// into r.Data(). The response is returned with its body closed, or nil if there
+// was none.
+func (w worker) attempt(r JSONRequest, request *http.Request) (resp *http.Response, err error) {
+	request = w.rewrite(request)
+
+	if w.budget != nil {
+		if err := w.budget.check(); err != nil {
+			return resp, err
+		}
+	}
+
+	if w.breaker != nil {
+		host := request.URL.Host
+		if err := w.breaker.allow(host); err != nil {
+			return resp, err
+		}
+		defer func() {
+			w.breaker.done(host, err)
+		}()
+	}
+
+	if w.hostBackoff != nil {
+		host := request.URL.Host
+		if err := w.hostBackoff.wait(request.Context(), host); err != nil {
+			return resp, errors.Wrap(err, "HTTP: Error waiting for host backoff")
+		}
+		defer func() {
+			w.hostBackoff.done(host, err)
+		}()
+	}
+
+	if w.hostLimit != nil {
+		release, err := w.hostLimit.acquire(request.Context(), request.URL.Host)
+		if err != nil {
+			return resp, errors.Wrap(err, "HTTP: Error waiting for host limit")
+		}
+		defer release()
+	}
+
+	if w.shared != nil {
+		release, err := acquireSem(request.Context(), w.shared)
+		if err != nil {
+			return resp, errors.Wrap(err, "HTTP: Error waiting for shared limit")
+		}
+		defer release()
+	}
+
+	if w.limiter != nil {
+		if err := w.waitRateLimit(request.Context()); err != nil {
+			return resp, errors.Wrap(err, "HTTP: Error waiting for rate limit")
+		}
+	}
+
+	if w.metrics != nil {
+		w.metrics.InFlight(1)
+		defer func(start time.Time) {
+			observe(w.metrics, start, err)
+		}(time.Now())
+	}
+
+	var status int
+	if w.tracer != nil {
+		ctx, finish := w.tracer.StartSpan(request.Context(), "HTTP "+request.Method)
+		request = request.WithContext(ctx)
+		defer func() {
+			finish(SpanInfo{URL: request.URL.String(), Status: status, Err: err})
+		}()
+	}
+
+	w.setDefaultQuery(request)
+	request = w.traceConn(request)
+	for _, hook := range w.requestHooks {
+		hook(request)
+	}
+	request = trackUpload(r, request)
+
+	resp, cached, err := w.send(r, request)
+	if err != nil {
+		return nil, &TransportError{Err: err}
+	}
+	if cs, ok := r.(CacheStatusReceiver); ok && w.cache != nil {
+		cs.SetCacheStatus(cached)
+	}
+
+	status = resp.StatusCode
+	if w.bodyTimeout > 0 {
+		resp.Body = newIdleBody(resp.Body, w.bodyTimeout)
+	}
+	if w.budget != nil && !cached {
+		resp.Body = budgetBody{rc: resp.Body, b: w.budget}
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	for _, hook := range w.responseHooks {
+		hook(resp)
+	}
+
+	if hr, ok := r.(HeaderReceiver); ok {
+		hr.SetResponseHeaders(resp.Header)
+	}
+	if mr, ok := r.(ResponseMetaReceiver); ok {
+		mr.SetResponseMeta(resp.StatusCode, resp.Proto, resp.ContentLength)
+	}
+	if fr, ok := r.(FinalURLReceiver); ok {
+		u := request.URL
+		if resp.Request != nil {
+			u = resp.Request.URL
+		}
+		fr.SetFinalURL(u)
+	}
+
+	if checkConditions(r, resp) {
+		return resp, nil
+	}
+
+	if !acceptable(r, resp.StatusCode) {
+		se := newStatusError(resp)
+		w.decodeErrorData(r, se)
+		if w.errorDump != "" {
+			se.DumpFile = w.dumpBody(request, se.Body)
+		}
+		return resp, se
+	}
+
+	// Responses to HEAD requests have no body to decode.
+	if request.Method == "HEAD" {
+		return resp, nil
+	}
+	if !w.decoder.requireBody &&
+		(resp.StatusCode == http.StatusNoContent || resp.ContentLength == 0) {
+		return resp, nil
+	}
+
+	var body io.Reader = resp.Body
+	if !w.decoder.requireBody && (w.decoder.allowEmpty || mayBeEmpty(request.Method)) {
+		var empty bool
+		var release func()
+		body, empty, release = peekEmpty(body)
+		defer release()
+		if empty {
+			return resp, nil
+		}
+	}
+	if w.decompress {
+		if body, err = decompress(resp, body, w.decompressors); err != nil {
+			return resp, newDecodeError(err, "HTTP: Error decompressing response")
+		}
+	}
+	if w.maxBody > 0 {
+		body = &limitedBody{r: body, n: w.maxBody}
+	}
+
+	if w.bufferBody {
+		if body, err = bufferBody(r, body); err != nil {
+			return resp, newDecodeError(err, "HTTP: Error reading response")
+		}
+	}
+
+	var dump *capBuffer
+	if w.errorDump != "" {
+		dump = &capBuffer{n: MaxErrorDumpSize}
+		body = io.TeeReader(body, dump)
+	}
+	var raw *capBuffer
+	if w.rawBody > 0 {
+		raw = &capBuffer{n: w.rawBody}
+		body = io.TeeReader(body, raw)
+	}
+	if err = w.decode(r, resp, body); err != nil {
+		if raw != nil {
+			attachRawBody(err, raw, body)
+		}
+		if dump != nil {
+			w.dumpDecodeError(err, request, dump, body)
+		}
+		return resp, err
+	}
+	return resp, readTrailers(r, resp, body)
 }
 
 // Pool manages a set of workers and provides an interface to schedule new
 // JSONRequest.
 type Pool struct {
-	in chan JSONRequest
-	wg *sync.WaitGroup
+	wg    *sync.WaitGroup
+	state *poolState
+	w     worker
+}
+
+// poolState guards the input queue of a Pool against being closed while
+// requests are scheduled.
+type poolState struct {
+	mu       sync.RWMutex
+	draining bool
+	in       chan JSONRequest
+	// queue is read by the workers. It is in, unless requests are
+	// dispatched by priority.
+	queue chan JSONRequest
+
+	// resize guards n, the current number of workers.
+	resize sync.Mutex
+	n      uint
 }
 
-// Stop closes the input queue and waits for the the workers to finish.
+// ErrPoolClosed is returned by Pool.Do once the Pool stopped accepting
+// requests.
+var ErrPoolClosed = errors.New("Pool is closed")
+
+// Stop closes the input queue and waits for the the workers to finish. Later
+// calls to Do return ErrPoolClosed until the Pool is restarted. Stopping a Pool
+// again has no effect.
 func (p Pool) Stop() {
-	close(p.in)
+	p.Drain()
+}
+
+// Results returns a channel that emits every request after its Done() was
+// called. Only requests that complete after the first call to Results are
+// emitted, and workers block until each one is received. The channel is closed
+// when the Pool is stopped.
+func (p Pool) Results() <-chan JSONRequest {
+	p.w.results.on.Store(true)
+	return p.w.results.channel()
+}
+
+// Drain stops accepting new requests and waits until all requests that are
+// already scheduled are done. Calls to Do that are still scheduling requests
+// are allowed to finish, later calls return ErrPoolClosed.
+func (p Pool) Drain() {
+	p.state.mu.Lock()
+	if p.state.draining {
+		p.state.mu.Unlock()
+		p.wg.Wait()
+		return
+	}
+	p.state.draining = true
+	close(p.state.in)
+	results := p.w.results.channel()
+	p.state.mu.Unlock()
+
+	p.wg.Wait()
+	close(results)
+}
+
+// Restart makes a stopped Pool accept requests again, with as many workers as
+// it had when it was stopped. It waits for the Pool to finish stopping first.
+// Results has to be called again to receive the results after the restart.
+// Restart has no effect if the Pool wasn't stopped.
+func (p Pool) Restart() {
+	p.state.resize.Lock()
+	defer p.state.resize.Unlock()
+
+	p.state.mu.RLock()
+	draining := p.state.draining
+	p.state.mu.RUnlock()
+	if !draining {
+		return
+	}
 	p.wg.Wait()
+
+	p.state.mu.Lock()
+	defer p.state.mu.Unlock()
+	p.state.draining = false
+	p.state.open(cap(p.state.in), p.w.priority)
+	// The old channel is closed, so results are only sent again once
+	// Results is called for the new one.
+	p.w.results.mu.Lock()
+	p.w.results.ch = make(chan JSONRequest)
+	p.w.results.on.Store(false)
+	p.w.results.mu.Unlock()
+
+	for i := uint(0); i < p.state.n; i++ {
+		p.wg.Add(1)
+		go p.w.run(p.state.queue, p.w.quit, p.wg)
+	}
+}
+
+// open creates the queues of the Pool.
+func (s *poolState) open(bufSize int, priority bool) {
+	s.in = make(chan JSONRequest, bufSize)
+	s.queue = s.in
+	if priority {
+		s.queue = make(chan JSONRequest)
+		go dispatch(s.in, s.queue)
+	}
 }
 
-// Do schedules new JSONRequest for the workers.
-func (p Pool) Do(rqs ...JSONRequest) {
+// Resize changes the number of workers to n. Additional workers are started
+// immediately, excess workers exit after finishing their current request.
+// Resize blocks until all excess workers received the signal to exit. It has
+// no effect once the Pool is stopped.
+func (p Pool) Resize(n uint) {
+	p.state.resize.Lock()
+	defer p.state.resize.Unlock()
+
+	p.state.mu.RLock()
+	defer p.state.mu.RUnlock()
+	if p.state.draining {
+		return
+	}
+
+	for ; p.state.n < n; p.state.n++ {
+		p.wg.Add(1)
+		go p.w.run(p.state.queue, p.w.quit, p.wg)
+	}
+	for ; p.state.n > n; p.state.n-- {
+		p.w.quit <- struct{}{}
+	}
+}
+
+// Do schedules new JSONRequest for the workers. It returns ErrPoolClosed if
+// the Pool is draining.
+func (p Pool) Do(rqs ...JSONRequest) error {
+	return p.DoCtx(context.Background(), rqs...)
+}
+
+// DoCtx works like Do, but gives up scheduling once ctx is done and returns
+// ctx.Err(). Requests that were not scheduled are left untouched.
+func (p Pool) DoCtx(ctx context.Context, rqs ...JSONRequest) error {
+	p.state.mu.RLock()
+	defer p.state.mu.RUnlock()
+	if p.state.draining {
+		return ErrPoolClosed
+	}
+
 	for _, rq := range rqs {
-		p.in <- rq
+		p.w.pending.add(1)
+		select {
+		case p.state.in <- rq:
+			p.w.counters.queued.Add(1)
+		case <-ctx.Done():
+			p.w.pending.add(-1)
+			return ctx.Err()
+		}
 	}
+	return nil
 }
 
-// NewPool creates a new Pool with n workers.
-func NewPool(n uint) Pool {
-	p := Pool{
-		in: make(chan JSONRequest),
-		wg: new(sync.WaitGroup),
+// TryDo schedules rq only if a worker or buffer space is available right away.
+// It reports whether rq was scheduled.
+func (p Pool) TryDo(rq JSONRequest) bool {
+	p.state.mu.RLock()
+	defer p.state.mu.RUnlock()
+	if p.state.draining {
+		return false
 	}
 
-	p.wg.Add(int(n))
-	for i := uint(0); i < n; i++ {
-		go Worker(p.in, p.wg)
+	p.w.pending.add(1)
+	select {
+	case p.state.in <- rq:
+		p.w.counters.queued.Add(1)
+		return true
+	default:
+		p.w.pending.add(-1)
+		return false
 	}
+}
+
+// NewPool creates a new Pool with n workers.
+func NewPool(n uint, opts ...Option) Pool {
+	return NewPoolContext(context.Background(), n, opts...)
+}
+
+// NewPoolContext creates a new Pool with n workers, that perform all requests
+// with ctx. Cancelling ctx aborts in-flight requests and fails all remaining
+// requests with a *CancelledError wrapping ctx.Err(). The Pool still has to be
+// stopped.
+func NewPoolContext(ctx context.Context, n uint, opts ...Option) Pool {
+	return newPool(n, 0, worker{ctx: ctx, client: http.DefaultClient}, opts)
+}
+
+// NewPoolWithClient creates a new Pool with n workers, that perform all
+// requests with client instead of http.DefaultClient.
+func NewPoolWithClient(n uint, client *http.Client, opts ...Option) Pool {
+	return newPool(n, 0, worker{ctx: context.Background(), client: client}, opts)
+}
+
+// NewPoolWithJar creates a new Pool with n workers, that share a client with a
+// cookie jar. Cookies set by one response are sent with all later requests, so
+// e.g. a login request can be followed by requests using that session. To
+// configure the client further, use NewPoolWithClient with a client that has
+// its Jar set.
+func NewPoolWithJar(n uint, opts ...Option) Pool {
+	// cookiejar.New never fails without options.
+	jar, _ := cookiejar.New(nil)
+	return NewPoolWithClient(n, &http.Client{Jar: jar}, opts...)
+}
+
+// NewPoolBuffered creates a new Pool with n workers and room for bufSize
+// scheduled requests that are waiting for a worker. Do only blocks once the
+// buffer is full, which provides backpressure to producers.
+func NewPoolBuffered(n uint, bufSize int, opts ...Option) Pool {
+	return newPool(n, bufSize, worker{ctx: context.Background(), client: http.DefaultClient}, opts)
+}
+
+func newPool(n uint, bufSize int, w worker, opts []Option) Pool {
+	w = newWorker(w, opts)
+	w.quit = make(chan struct{})
+	w.results = &results{ch: make(chan JSONRequest)}
+	w.counters = new(counters)
+	w.pending = newPending()
+	w.activity = &activity{workers: make(map[*workerActivity]struct{})}
+
+	p := Pool{
+		wg:    new(sync.WaitGroup),
+		state: new(poolState),
+		w:     w,
+	}
+	p.state.open(bufSize, w.priority)
+	p.Resize(n)
 
 	return p
 }
 
+// MaxDoWorkers limits the number of workers started by Do and the other
+// package level functions performing a batch of requests, so that large
+// batches don't start a goroutine per request. Use DoN to choose the number of
+// workers explicitly.
+var MaxDoWorkers = 8 * runtime.NumCPU()
+
+// doWorkers returns the number of workers to perform n requests with.
+func doWorkers(n int) uint {
+	if n > MaxDoWorkers {
+		n = MaxDoWorkers
+	}
+	if n < 1 {
+		n = 1
+	}
+	return uint(n)
+}
+
 // Do will start a pool, schedule all provided JSONRequest and waits for their
-// completion
+// completion. At most MaxDoWorkers requests are performed at a time.
 func Do(rqs ...JSONRequest) {
-	p := NewPool(uint(len(rqs)))
+	p := NewPool(doWorkers(len(rqs)))
+	p.Do(rqs...)
+	p.Stop()
+}
+
+// DoCollect works like Do and returns the error of each request in the order of
+// rqs. The error of a successful request is nil. A panic while performing a
+// request is treated as a bug that fails the whole batch, like with
+// WithPanicPropagation: the requests that are not done yet fail as well, and
+// the unperformed ones with the same *PanicError.
+func DoCollect(rqs ...JSONRequest) []error {
+	collect(context.Background(), doWorkers(len(rqs)), rqs)
+	return collectErrs(rqs)
+}
+
+// DoContext works like DoCollect, but performs rqs with ctx. Once ctx is done,
+// requests in flight are aborted and the remaining ones fail without being
+// performed, with a *DeadlineError or a *CancelledError.
+func DoContext(ctx context.Context, rqs ...JSONRequest) []error {
+	collect(ctx, doWorkers(len(rqs)), rqs)
+	return collectErrs(rqs)
+}
+
+// collect performs rqs with n workers and ctx for the collecting Do variants,
+// which propagate panics.
+func collect(ctx context.Context, n uint, rqs []JSONRequest) {
+	if n < 1 {
+		n = 1
+	}
+	p := NewPoolContext(ctx, n, WithPanicPropagation())
 	p.Do(rqs...)
 	p.Stop()
 }
 
+func collectErrs(rqs []JSONRequest) []error {
+	errs := make([]error, len(rqs))
+	for i, rq := range rqs {
+		errs[i] = rq.Err()
+	}
+	return errs
+}
+
+// DoOrdered works like Do, but returns immediately. Every request is sent on
+// the returned channel once it is done and all requests before it in rqs were
+// sent. The channel is closed after the last request.
+func DoOrdered(rqs ...JSONRequest) <-chan JSONRequest {
+	w := newWorker(worker{ctx: context.Background(), client: http.DefaultClient}, nil)
+	done := make([]chan struct{}, len(rqs))
+	next := make(chan int, len(rqs))
+	for i := range rqs {
+		done[i] = make(chan struct{})
+		next <- i
+	}
+	close(next)
+	for range doWorkers(len(rqs)) {
+		go func() {
+			for i := range next {
+				w.handle(rqs[i])
+				close(done[i])
+			}
+		}()
+	}
+
+	out := make(chan JSONRequest, len(rqs))
+	go func() {
+		for i, rq := range rqs {
+			<-done[i]
+			out <- rq
+		}
+		close(out)
+	}()
+	return out
+}
+
+// DoFunc pulls requests from next until it returns false, and performs at
+// most n of them at a time. Requests are only pulled once a worker is ready to
+// take them, so the number of requests held in memory stays bounded.
+func DoFunc(n uint, next func() (JSONRequest, bool)) {
+	if n < 1 {
+		n = 1
+	}
+	p := NewPool(n)
+	for {
+		rq, ok := next()
+		if !ok {
+			break
+		}
+		p.Do(rq)
+	}
+	p.Stop()
+}
+
+// DoProgress works like Do and calls progress each time a request is done with
+// the number of requests done so far and the total number of requests. Calls
+// to progress are serialized.
+func DoProgress(progress func(done, total int), rqs ...JSONRequest) {
+	var mu sync.Mutex
+	done := 0
+	p := NewPool(doWorkers(len(rqs)), func(w *worker) {
+		w.completed = func(JSONRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			progress(done, len(rqs))
+		}
+	})
+	p.Do(rqs...)
+	p.Stop()
+}
+
+// DoTimeout works like DoCollect, but aborts all requests that are not done
+// after d. Their error is a *DeadlineError.
+func DoTimeout(d time.Duration, rqs ...JSONRequest) []error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	collect(ctx, doWorkers(len(rqs)), rqs)
+	return collectErrs(rqs)
+}
+
+// DoFailFast works like Do, but cancels all remaining requests as soon as one
+// request fails and returns the error of that request. The cancelled requests
+// fail with a cancellation error.
+func DoFailFast(ctx context.Context, rqs ...JSONRequest) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var first error
+	p := NewPoolContext(ctx, doWorkers(len(rqs)), func(w *worker) {
+		w.completed = func(r JSONRequest) {
+			if err := r.Err(); err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}
+	})
+	p.Do(rqs...)
+	p.Stop()
+	return first
+}
+
+// Process performs rq synchronously with client and ctx, like a worker of a
+// Pool created with opts would, and returns its error. Unlike a worker, it
+// doesn't call rq.Done(). A nil client means http.DefaultClient.
+func Process(ctx context.Context, client *http.Client, rq JSONRequest, opts ...Option) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	w := newWorker(worker{ctx: ctx, client: client}, opts)
+	return w.execute(rq)
+}
+
+// DoSequential performs rqs one after another in the calling goroutine, in
+// the order given, without starting any workers. Otherwise requests are
+// processed exactly like by a Pool.
+func DoSequential(rqs ...JSONRequest) {
+	w := newWorker(worker{ctx: context.Background(), client: http.DefaultClient}, nil)
+	for _, rq := range rqs {
+		w.handle(rq)
+	}
+}
+
 // DoN will schedule at most n requests at a time
 func DoN(n uint, rqs ...JSONRequest) {
 	if n < 1 {
@@ -126,3 +1468,88 @@ func DoN(n uint, rqs ...JSONRequest) {
 	p.Do(rqs...)
 	p.Stop()
 }
+
+// DoNCtx works like DoN, but stops scheduling requests once ctx is done and
+// returns ctx.Err() in that case. Like in a Pool created with NewPoolContext,
+// scheduled requests that are not done yet fail with a *DeadlineError or a
+// *CancelledError. The requests that were not scheduled anymore fail with the
+// same error, but are not marked as done.
+func DoNCtx(ctx context.Context, n uint, rqs ...JSONRequest) error {
+	if n < 1 {
+		n = 1
+	}
+	p := NewPoolContext(ctx, n)
+	defer p.Stop()
+	for i, rq := range rqs {
+		if err := p.DoCtx(ctx, rq); err != nil {
+			for _, rq := range rqs[i:] {
+				rq.SetErr(contextError(err))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Result is a request performed by RunBatch with its error, which is nil if
+// the request succeeded.
+type Result struct {
+	Request JSONRequest
+	Err     error
+}
+
+// RunBatch performs rqs with n workers, waits until all are done and returns
+// a Result for every request, in the order of rqs. Like with DoCollect, a panic
+// fails the whole batch.
+func RunBatch(n uint, rqs ...JSONRequest) []Result {
+	collect(context.Background(), n, rqs)
+	results := make([]Result, len(rqs))
+	for i, rq := range rqs {
+		results[i] = Result{Request: rq, Err: rq.Err()}
+	}
+	return results
+}
+
+// DoNCollect works like DoN and returns the requests that failed, in the order
+// of rqs, e.g. to retry just those. The error is a *MultiError of their
+// errors, in the same order, and nil if all succeeded. Like with DoCollect, a
+// panic fails the whole batch.
+func DoNCollect(n uint, rqs ...JSONRequest) ([]JSONRequest, error) {
+	collect(context.Background(), n, rqs)
+
+	var failed []JSONRequest
+	var errs []error
+	for _, rq := range rqs {
+		if err := rq.Err(); err != nil {
+			failed = append(failed, rq)
+			errs = append(errs, err)
+		}
+	}
+	return failed, newMultiError(errs)
+}
+
+// DoMap works like Do for all requests in rqs and returns their errors under
+// the same keys. The error of a successful request is nil. A request stored
+// under several keys is only performed once.
+func DoMap(rqs map[string]JSONRequest) map[string]error {
+	list := make([]JSONRequest, 0, len(rqs))
+	seen := make(map[JSONRequest]bool, len(rqs))
+	for _, rq := range rqs {
+		// Only pointers can be shared between keys, and they are always
+		// comparable.
+		if reflect.ValueOf(rq).Kind() == reflect.Pointer {
+			if seen[rq] {
+				continue
+			}
+			seen[rq] = true
+		}
+		list = append(list, rq)
+	}
+	Do(list...)
+
+	errs := make(map[string]error, len(rqs))
+	for k, rq := range rqs {
+		errs[k] = rq.Err()
+	}
+	return errs
+}
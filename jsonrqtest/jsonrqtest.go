@@ -0,0 +1,56 @@
+// Package jsonrqtest provides utilities for testing code that performs
+// requests with jsonrq, without a server or network access.
+package jsonrqtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lemmi/jsonrq"
+)
+
+// RoundTripFunc is an http.RoundTripper answering every request with the
+// result of calling itself.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f.
+func (f RoundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// NewMockPool creates a jsonrq.Pool with n workers, that sends all requests to
+// rt instead of the network.
+func NewMockPool(rt http.RoundTripper, n uint, opts ...jsonrq.Option) jsonrq.Pool {
+	return jsonrq.NewPoolWithClient(n, &http.Client{Transport: rt}, opts...)
+}
+
+// Response returns a response to r with the given status and body, which is
+// sent with Content-Type application/json.
+func Response(r *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
+
+// JSON returns a RoundTripFunc answering every request with status and v
+// encoded as JSON.
+func JSON(status int, v interface{}) RoundTripFunc {
+	b, err := json.Marshal(v)
+	return func(r *http.Request) (*http.Response, error) {
+		if err != nil {
+			return nil, err
+		}
+		return Response(r, status, string(b)), nil
+	}
+}
@@ -0,0 +1,28 @@
+package jsonrq
+
+// Logger receives log messages of the workers.
+type Logger interface {
+	Logf(format string, args ...interface{})
+}
+
+// LoggerFunc adapts a printf style function, like log.Printf, to a Logger.
+type LoggerFunc func(format string, args ...interface{})
+
+// Logf calls f.
+func (f LoggerFunc) Logf(format string, args ...interface{}) {
+	f(format, args...)
+}
+
+// WithLogger logs the start and completion of every request, retries and
+// errors to l. By default nothing is logged.
+func WithLogger(l Logger) Option {
+	return func(w *worker) {
+		w.logger = l
+	}
+}
+
+func (w worker) logf(format string, args ...interface{}) {
+	if w.logger != nil {
+		w.logger.Logf(format, args...)
+	}
+}
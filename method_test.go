@@ -0,0 +1,92 @@
+package jsonrq
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func methodServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if b, _ := io.ReadAll(r.Body); r.URL.Query().Has("send") && string(b) != `{"in":1}` {
+			t.Errorf("%s: got request body %q", r.Method, b)
+		}
+
+		q := r.URL.Query()
+		switch {
+		case q.Has("chunked"):
+			// Send the headers without a Content-Length and no body.
+			w.(http.Flusher).Flush()
+		case q.Has("empty"):
+		case q.Has("gzip"):
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			io.WriteString(zw, `{"method":"`+r.Method+`"}`)
+			zw.Close()
+		default:
+			io.WriteString(w, `{"method":"`+r.Method+`"}`)
+		}
+	}))
+}
+
+func TestMethods(t *testing.T) {
+	srv := methodServer(t)
+	defer srv.Close()
+
+	methods := []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "PURGE"}
+	cases := []struct {
+		name  string
+		query string
+		// decoded reports whether the response is decoded for method.
+		decoded func(method string) bool
+		// ok reports whether the request succeeds for method.
+		ok func(method string) bool
+	}{
+		{"json", "", notHead, always},
+		{"request body", "?send", notHead, always},
+		{"gzip", "?gzip", notHead, always},
+		{"empty", "?empty", never, always},
+		{"chunked empty", "?chunked", never, func(m string) bool {
+			return m == "HEAD" || mayBeEmpty(m)
+		}},
+	}
+
+	for _, c := range cases {
+		for _, m := range methods {
+			t.Run(c.name+"/"+m, func(t *testing.T) {
+				rq := &TypedRequest[map[string]string]{
+					BasicRequest: NewBasicRequestWithMethod(m, srv.URL+c.query),
+				}
+				switch c.query {
+				case "?send":
+					rq.SetBody(map[string]int{"in": 1})
+				case "?gzip":
+					// Keep the transport from decompressing the body itself.
+					rq.AddHeader("Accept-Encoding", "gzip")
+				}
+
+				err := Process(context.Background(), nil, rq, WithDecompression())
+				if got := err == nil; got != c.ok(m) {
+					t.Fatalf("got error %v, want success %v", err, c.ok(m))
+				}
+				if err != nil {
+					return
+				}
+				want := ""
+				if c.decoded(m) {
+					want = m
+				}
+				if got := rq.Result()["method"]; got != want {
+					t.Errorf("got decoded method %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+func always(string) bool    { return true }
+func never(string) bool     { return false }
+func notHead(m string) bool { return m != "HEAD" }
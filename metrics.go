@@ -0,0 +1,45 @@
+package jsonrq
+
+import (
+	"time"
+)
+
+// Outcomes reported to Metrics.RequestsTotal.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Metrics receives measurements of every attempt to perform a request. It is
+// small enough to be implemented with prometheus collectors or any other
+// metrics library. All methods may be called concurrently.
+type Metrics interface {
+	// RequestsTotal counts a finished attempt by outcome.
+	RequestsTotal(outcome string)
+	// RequestDuration observes the duration of a finished attempt,
+	// including decoding the response.
+	RequestDuration(d time.Duration)
+	// InFlight changes the number of running attempts by delta.
+	InFlight(delta int)
+	// Errors counts a failed attempt.
+	Errors(err error)
+}
+
+// WithMetrics reports measurements of all workers to m.
+func WithMetrics(m Metrics) Option {
+	return func(w *worker) {
+		w.metrics = m
+	}
+}
+
+// observe reports the outcome of an attempt started at start.
+func observe(m Metrics, start time.Time, err error) {
+	m.InFlight(-1)
+	m.RequestDuration(time.Since(start))
+	if err != nil {
+		m.RequestsTotal(OutcomeError)
+		m.Errors(err)
+		return
+	}
+	m.RequestsTotal(OutcomeSuccess)
+}
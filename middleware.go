@@ -0,0 +1,34 @@
+package jsonrq
+
+// Handler processes a JSONRequest and returns its error.
+type Handler func(r JSONRequest) error
+
+// Middleware wraps a Handler, e.g. to add logging, metrics or authentication
+// around the processing of every request.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware wraps the processing of every request, including its retries,
+// in mw. The first Middleware is the outermost one. The error returned by the
+// chain becomes the error of the request.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(w *worker) {
+		w.middleware = append(w.middleware, mw...)
+	}
+}
+
+// serve processes r through the middleware chain.
+func (w worker) serve(r JSONRequest) (err error) {
+	if len(w.middleware) == 0 {
+		return w.process(r)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = w.recovered(p)
+		}
+	}()
+	h := Handler(w.process)
+	for i := len(w.middleware) - 1; i >= 0; i-- {
+		h = w.middleware[i](h)
+	}
+	return h(r)
+}
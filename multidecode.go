@@ -0,0 +1,27 @@
+package jsonrq
+
+import (
+	"bytes"
+)
+
+// MultiDecodeRequest can be implemented by a JSONRequest whose endpoint answers
+// with different schemas for success and failure. Successful responses are
+// decoded into SuccessData instead of Data. The body of an unsuccessful
+// response is decoded into ErrorData, up to MaxStatusErrorBody bytes, and the
+// request still fails with a *StatusError. An error body that can't be decoded
+// leaves ErrorData untouched.
+type MultiDecodeRequest interface {
+	JSONRequest
+	SuccessData() interface{}
+	ErrorData() interface{}
+}
+
+// decodeErrorData decodes the body of se into the ErrorData of r, if it is a
+// MultiDecodeRequest.
+func (w worker) decodeErrorData(r JSONRequest, se *StatusError) {
+	md, ok := r.(MultiDecodeRequest)
+	if !ok || len(se.Body) == 0 {
+		return
+	}
+	w.decodeJSON(bytes.NewReader(se.Body), md.ErrorData())
+}
@@ -0,0 +1,90 @@
+package jsonrq
+
+import (
+	"io"
+	"mime/multipart"
+	"sync"
+)
+
+// part is a field or file of a multipart/form-data body.
+type part struct {
+	name     string
+	value    string
+	filename string
+	file     io.Reader
+}
+
+// AddFormField adds a field to a multipart/form-data body. It replaces a body
+// set with SetBody or SetFormBody.
+func (r *BasicRequest) AddFormField(name, value string) {
+	r.addPart(part{name: name, value: value})
+}
+
+// AddFormFile adds a file read from file to a multipart/form-data body. It
+// replaces a body set with SetBody or SetFormBody.
+//
+// The body is streamed while the request is sent, so files are never held in
+// memory. As a consequence, the body can't be replayed: the request is not
+// retried, and following a 307 or 308 redirect fails.
+func (r *BasicRequest) AddFormFile(name, filename string, file io.Reader) {
+	r.addPart(part{name: name, filename: filename, file: file})
+}
+
+func (r *BasicRequest) addPart(p part) {
+	if r.parts == nil {
+		r.clearBody()
+	}
+	r.parts = append(r.parts, p)
+}
+
+// multipartBody streams parts as multipart/form-data body and returns it with
+// its Content-Type.
+func multipartBody(parts []part) (io.Reader, string) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	return &partsBody{pr: pr, pw: pw, mw: mw, parts: parts}, mw.FormDataContentType()
+}
+
+// partsBody writes the parts into the pipe only once the body is read, so
+// that requests which are never sent don't leave a writer behind.
+type partsBody struct {
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+	mw    *multipart.Writer
+	parts []part
+	once  sync.Once
+}
+
+func (b *partsBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go func() {
+			b.pw.CloseWithError(writeParts(b.mw, b.parts))
+		}()
+	})
+	return b.pr.Read(p)
+}
+
+// Close aborts a writer that is still running.
+func (b *partsBody) Close() error {
+	return b.pr.CloseWithError(io.ErrClosedPipe)
+}
+
+func writeParts(mw *multipart.Writer, parts []part) error {
+	for _, p := range parts {
+		if p.file == nil {
+			if err := mw.WriteField(p.name, p.value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fw, err := mw.CreateFormFile(p.name, p.filename)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(fw, p.file); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
@@ -0,0 +1,48 @@
+package jsonrq
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// MultipartResponseRequest can be implemented by a JSONRequest to decode
+// multipart responses, e.g. multipart/mixed responses of batch APIs bundling
+// several JSON documents. PartData is called with the headers of every part,
+// in order, and returns the value the part is decoded into, or nil to skip the
+// part. Data() is not used.
+type MultipartResponseRequest interface {
+	JSONRequest
+	PartData(header textproto.MIMEHeader) interface{}
+}
+
+// decodeParts decodes the parts of the multipart body of resp for mr.
+func (w worker) decodeParts(mr MultipartResponseRequest, resp *http.Response, body io.Reader) error {
+	ct := resp.Header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(ct)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return &ContentTypeError{ContentType: ct}
+	}
+
+	parts := multipart.NewReader(body, params["boundary"])
+	for {
+		p, err := parts.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return newDecodeError(err, "HTTP: Error reading multipart response")
+		}
+		if data := mr.PartData(p.Header); data != nil {
+			if err := w.decodeJSON(p, data); err != nil {
+				return newDecodeError(err, "JSON: Error decoding part of response")
+			}
+		}
+		if _, err := io.Copy(io.Discard, p); err != nil {
+			return newDecodeError(err, "HTTP: Error reading multipart response")
+		}
+	}
+}
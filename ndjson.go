@@ -0,0 +1,58 @@
+package jsonrq
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// NDJSONRequest consumes a stream of newline-delimited JSON values, calling a
+// callback with every record as soon as it is decoded. The stream is read until
+// the server ends it or the request's context is done, so long-lived streams
+// should not be used with a client timeout. A non-nil error from the callback
+// stops reading and fails the request.
+//
+// Records already passed to the callback are passed again if a failed request
+// is retried.
+type NDJSONRequest[T any] struct {
+	BasicRequest
+	record  func(T) error
+	records int
+}
+
+// NewNDJSONRequest creates an NDJSONRequest fetching url and calling record
+// with every record.
+func NewNDJSONRequest[T any](url string, record func(T) error) *NDJSONRequest[T] {
+	return &NDJSONRequest[T]{
+		BasicRequest: NewBasicRequest(url),
+		record:       record,
+	}
+}
+
+// Data returns nil, the records are decoded by Stream.
+func (r *NDJSONRequest[T]) Data() interface{} {
+	return nil
+}
+
+// Stream decodes records one by one until the end of the stream.
+func (r *NDJSONRequest[T]) Stream(dec *json.Decoder) error {
+	for {
+		var rec T
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		r.records++
+		if err := r.record(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// Done does nothing.
+func (r *NDJSONRequest[T]) Done() {}
+
+// Records returns the number of records decoded so far.
+func (r *NDJSONRequest[T]) Records() int {
+	return r.records
+}
@@ -0,0 +1,136 @@
+package jsonrq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PagedRequest can be implemented by a JSONRequest that spans several
+// responses. After each successful response, the worker performs the request
+// returned by NextPage, until it returns nil. All pages are fetched by the same
+// worker, one after another.
+type PagedRequest interface {
+	JSONRequest
+	NextPage() *http.Request
+}
+
+// PaginatedRequest fetches a JSON array that is split over several pages and
+// collects the elements of all pages. The next page is found in the Link
+// header of each response (RFC 5988) with rel="next".
+type PaginatedRequest[T any] struct {
+	BasicRequest
+
+	// MaxPages limits the number of pages that are fetched. Zero means no
+	// limit.
+	MaxPages int
+
+	items []T
+	pages int
+	next  string
+	// cursor is the URL of the page fetched last, which relative links are
+	// resolved against. It is kept apart from the URL of the request, so
+	// that performing the request again starts from the first page.
+	cursor string
+}
+
+// NewPaginatedRequest creates a PaginatedRequest starting at url fetching at
+// most maxPages pages.
+func NewPaginatedRequest[T any](url string, maxPages int) *PaginatedRequest[T] {
+	return &PaginatedRequest[T]{
+		BasicRequest: NewBasicRequest(url),
+		MaxPages:     maxPages,
+	}
+}
+
+// Request prepares the request for the first page and discards the items of
+// an earlier run.
+func (r *PaginatedRequest[T]) Request() *http.Request {
+	r.items, r.pages, r.next, r.cursor = nil, 0, "", ""
+	return r.BasicRequest.Request()
+}
+
+// Data returns nil, the pages are decoded by Stream.
+func (r *PaginatedRequest[T]) Data() interface{} {
+	return nil
+}
+
+// Stream decodes a single page and appends its elements.
+func (r *PaginatedRequest[T]) Stream(dec *json.Decoder) error {
+	var page []T
+	if err := dec.Decode(&page); err != nil {
+		return err
+	}
+	r.items = append(r.items, page...)
+	r.pages++
+	return nil
+}
+
+// SetResponseHeaders looks up the link to the next page.
+func (r *PaginatedRequest[T]) SetResponseHeaders(header http.Header) {
+	r.next = nextLink(header)
+}
+
+// NextPage returns the request for the next page, or nil if there is none or
+// MaxPages is reached.
+func (r *PaginatedRequest[T]) NextPage() *http.Request {
+	if r.next == "" || (r.MaxPages > 0 && r.pages >= r.MaxPages) {
+		return nil
+	}
+
+	next, err := url.Parse(r.next)
+	r.next = ""
+	if err != nil {
+		return nil
+	}
+	base := r.cursor
+	if base == "" {
+		base = r.url
+	}
+	if base, err := url.Parse(base); err == nil {
+		next = base.ResolveReference(next)
+	}
+
+	// The link to the next page already carries all query parameters.
+	r.cursor = next.String()
+	return r.newRequest(r.cursor)
+}
+
+// Done does nothing. Read the Items once the request was processed.
+func (r *PaginatedRequest[T]) Done() {}
+
+// Items returns the elements of all fetched pages.
+func (r *PaginatedRequest[T]) Items() []T {
+	return r.items
+}
+
+// Pages returns the number of fetched pages.
+func (r *PaginatedRequest[T]) Pages() int {
+	return r.pages
+}
+
+// nextLink returns the target of the Link with rel="next" in header.
+func nextLink(header http.Header) string {
+	for _, v := range header.Values("Link") {
+		for _, link := range strings.Split(v, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(k, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(v, `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package jsonrq
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+)
+
+// PanicError is recorded for requests whose processing panicked.
+type PanicError struct {
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// WithPanicPropagation treats a panic while processing a request as fatal for
+// the whole Pool: the panicking request fails with a *PanicError, the context
+// of the Pool is cancelled with it, so that in-flight requests are aborted and
+// all remaining requests fail with the same *PanicError, and Pool.Err returns
+// it. Without it, only the panicking request fails. The collecting Do variants,
+// like DoCollect and RunBatch, always propagate panics.
+func WithPanicPropagation() Option {
+	return func(w *worker) {
+		w.propagatePanics = true
+	}
+}
+
+// recovered turns the recovered panic value p into an error and propagates it
+// if configured.
+func (w worker) recovered(p interface{}) error {
+	pe := &PanicError{Value: p, Stack: debug.Stack()}
+	if w.cancelPool != nil {
+		w.cancelPool(pe)
+	}
+	return pe
+}
+
+// Err returns the *PanicError that stopped the Pool, if it was created
+// WithPanicPropagation and a request panicked, and nil otherwise.
+func (p Pool) Err() error {
+	if p.w.cancelPool == nil {
+		return nil
+	}
+	var pe *PanicError
+	if errors.As(context.Cause(p.w.ctx), &pe) {
+		return pe
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package jsonrq
+
+import (
+	"container/heap"
+)
+
+// PriorityRequest can be implemented by a JSONRequest to be dispatched before
+// requests of lower priority, if the Pool was created WithPriority. Requests
+// without a priority have priority 0.
+type PriorityRequest interface {
+	JSONRequest
+	Priority() int
+}
+
+// WithPriority dispatches waiting requests by descending priority instead of
+// in the order they were scheduled. Requests of equal priority are dispatched
+// in the order they were scheduled. Scheduled requests are queued without
+// limit until a worker is available, so Do does not block.
+func WithPriority() Option {
+	return func(w *worker) {
+		w.priority = true
+	}
+}
+
+func priorityOf(r JSONRequest) int {
+	if pr, ok := unwrapFuture(r).(PriorityRequest); ok {
+		return pr.Priority()
+	}
+	return 0
+}
+
+// dispatch queues all requests received from in and sends them to out by
+// priority. out is closed once in is closed and all requests were sent.
+func dispatch(in <-chan JSONRequest, out chan<- JSONRequest) {
+	defer close(out)
+
+	var q priorityQueue
+	var seq uint64
+	for in != nil || q.Len() > 0 {
+		var send chan<- JSONRequest
+		var next JSONRequest
+		if q.Len() > 0 {
+			send = out
+			next = q[0].r
+		}
+
+		select {
+		case r, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			heap.Push(&q, queued{r: r, prio: priorityOf(r), seq: seq})
+			seq++
+		case send <- next:
+			heap.Pop(&q)
+		}
+	}
+}
+
+type queued struct {
+	r    JSONRequest
+	prio int
+	seq  uint64
+}
+
+// priorityQueue implements heap.Interface, ordering by descending priority and
+// ascending sequence number.
+type priorityQueue []queued
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].prio != q[j].prio {
+		return q[i].prio > q[j].prio
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *priorityQueue) Push(x interface{}) { *q = append(*q, x.(queued)) }
+
+func (q *priorityQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	old[len(old)-1] = queued{}
+	*q = old[:len(old)-1]
+	return x
+}
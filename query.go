@@ -0,0 +1,41 @@
+package jsonrq
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// WithDefaultQuery adds the parameters in query to the URL of every request
+// that doesn't set them itself. Parameters of the request take precedence,
+// key by key.
+func WithDefaultQuery(query url.Values) Option {
+	return func(w *worker) {
+		if w.defaultQuery == nil {
+			w.defaultQuery = make(url.Values)
+		}
+		for k, vs := range query {
+			w.defaultQuery[k] = append([]string(nil), vs...)
+		}
+	}
+}
+
+// setDefaultQuery adds the default query parameters missing in request.
+func (w worker) setDefaultQuery(request *http.Request) {
+	if len(w.defaultQuery) == 0 {
+		return
+	}
+	q := request.URL.Query()
+	missing := make(url.Values)
+	for k, vs := range w.defaultQuery {
+		if _, ok := q[k]; !ok {
+			missing[k] = vs
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	if request.URL.RawQuery != "" {
+		request.URL.RawQuery += "&"
+	}
+	request.URL.RawQuery += missing.Encode()
+}
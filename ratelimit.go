@@ -0,0 +1,40 @@
+package jsonrq
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit limits the rate of requests performed by all workers of the
+// Pool combined to r per second, allowing bursts of up to burst requests.
+// Every attempt of a retried request counts against the limit.
+func WithRateLimit(r rate.Limit, burst int) Option {
+	return func(w *worker) {
+		w.limiter = rate.NewLimiter(r, burst)
+	}
+}
+
+// WithRateLimitJitter delays every request by a random duration of up to max
+// after it passed the rate limit, so that workers woken at once don't fire in
+// lockstep. Requests are only ever delayed further, so the rate never exceeds
+// the one set with WithRateLimit. It has no effect without WithRateLimit.
+func WithRateLimitJitter(max time.Duration) Option {
+	return func(w *worker) {
+		w.jitter = max
+	}
+}
+
+// waitRateLimit waits until the rate limit allows another request plus the
+// configured jitter.
+func (w worker) waitRateLimit(ctx context.Context) error {
+	if err := w.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	if w.jitter <= 0 {
+		return nil
+	}
+	return sleep(ctx, time.Duration(rand.Int63n(int64(w.jitter)+1)))
+}
@@ -0,0 +1,86 @@
+package jsonrq
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Reset clears the request for reuse, including its URL, method, body, headers
+// and error. The header map is kept to save an allocation.
+func (r *BasicRequest) Reset() {
+	h := r.Header
+	for k := range h {
+		delete(h, k)
+	}
+	*r = BasicRequest{Header: h}
+}
+
+// Reset clears the request and its result for reuse.
+func (r *TypedRequest[T]) Reset() {
+	r.BasicRequest.Reset()
+	var zero T
+	r.result = zero
+}
+
+// Clone returns a copy of the request with the same URL, method, query,
+// headers, Host, authorization and body, including its compression, but none
+// of its results, such as the error. The header map and query values are
+// copied, so they can be modified without affecting the original. A body
+// reader set with SetBodyReader is shared.
+func (r *BasicRequest) Clone() BasicRequest {
+	return BasicRequest{
+		url:        r.url,
+		method:     r.method,
+		body:       r.body,
+		reader:     r.reader,
+		readerType: r.readerType,
+		form:       cloneValues(r.form),
+		parts:      append([]part(nil), r.parts...),
+		query:      cloneValues(r.query),
+		auth:       r.auth,
+		host:       r.host,
+		gzipBody:   r.gzipBody,
+		spoolBody:  r.spoolBody,
+		Header:     r.Header.Clone(),
+	}
+}
+
+func cloneValues(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+	return url.Values(http.Header(v).Clone())
+}
+
+// Clone returns a copy of the request like BasicRequest.Clone, with a zero
+// result.
+func (r *TypedRequest[T]) Clone() *TypedRequest[T] {
+	return &TypedRequest[T]{BasicRequest: r.BasicRequest.Clone()}
+}
+
+// TypedRequestPool recycles TypedRequests to reduce allocations when
+// performing many requests. The zero value is ready to use.
+//
+// A request may be returned with Put once it is done, i.e. Done() was called,
+// and its Result and Err are no longer needed. A request must not be used
+// after it was returned.
+type TypedRequestPool[T any] struct {
+	p sync.Pool
+}
+
+// Get returns a reset TypedRequest for url.
+func (p *TypedRequestPool[T]) Get(url string) *TypedRequest[T] {
+	r, ok := p.p.Get().(*TypedRequest[T])
+	if !ok {
+		return NewTypedRequest[T](url)
+	}
+	r.url = url
+	return r
+}
+
+// Put resets r and returns it to the pool.
+func (p *TypedRequestPool[T]) Put(r *TypedRequest[T]) {
+	r.Reset()
+	p.p.Put(r)
+}
@@ -0,0 +1,49 @@
+package jsonrq
+
+import "net/http"
+
+// RequestIDHeader is the header carrying the correlation ID of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDReceiver can be implemented by a JSONRequest to learn the
+// correlation ID sent with it when WithRequestIDGenerator is used.
+type RequestIDReceiver interface {
+	JSONRequest
+	SetRequestID(id string)
+}
+
+// SetRequestID is called by the workers with the correlation ID sent with the
+// request.
+func (r *BasicRequest) SetRequestID(id string) {
+	r.requestID = id
+}
+
+// RequestID returns the correlation ID sent with the request.
+func (r *BasicRequest) RequestID() string {
+	return r.requestID
+}
+
+// WithRequestIDGenerator sets an X-Request-ID header generated by gen on every
+// request that doesn't set its own. The ID is the same for all retries of a
+// request and is passed to requests implementing RequestIDReceiver.
+func WithRequestIDGenerator(gen func() string) Option {
+	return func(w *worker) {
+		w.requestID = gen
+	}
+}
+
+// setRequestID adds a generated correlation ID to request if needed and
+// reports it to r.
+func (w worker) setRequestID(r JSONRequest, request *http.Request) {
+	if w.requestID == nil {
+		return
+	}
+	id := request.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = w.requestID()
+		request.Header.Set(RequestIDHeader, id)
+	}
+	if rr, ok := r.(RequestIDReceiver); ok {
+		rr.SetRequestID(id)
+	}
+}
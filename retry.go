@@ -0,0 +1,274 @@
+package jsonrq
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetryableRequest can be implemented by a JSONRequest to override the number
+// of retries configured for the Pool.
+type RetryableRequest interface {
+	JSONRequest
+	MaxRetries() int
+}
+
+// IdempotentRequest can be implemented by a JSONRequest to declare whether it
+// may be retried, regardless of its method.
+type IdempotentRequest interface {
+	JSONRequest
+	Idempotent() bool
+}
+
+// RetryDecider can be implemented by a JSONRequest to decide itself which
+// failures are retried, instead of IsRetryable. ShouldRetry is called after
+// every failed attempt, numbered from 1, with its response, which is nil if
+// there was none, and its error. The number of retries is still limited by
+// WithRetries or MaxRetries, but the method of the request doesn't matter.
+type RetryDecider interface {
+	JSONRequest
+	ShouldRetry(resp *http.Response, err error, attempt int) bool
+}
+
+// RetryObserver can be implemented by a JSONRequest to be notified before it is
+// retried. OnRetry is called with the number of the failed attempt, counted
+// from 1, its error and the delay before the next attempt.
+type RetryObserver interface {
+	JSONRequest
+	OnRetry(attempt int, err error, delay time.Duration)
+}
+
+// WithRetries makes the workers retry each request up to n times on
+// transient failures, i.e. network errors, 5xx and 429 responses. The
+// Retry-After header of 429 and 503 responses is respected if it asks for a
+// longer delay than the backoff. Only requests with an idempotent method
+// (GET, HEAD, OPTIONS, TRACE, PUT and DELETE) or an Idempotency-Key header are
+// retried, unless WithRetryNonIdempotent is used or the request implements
+// IdempotentRequest.
+//
+// Use IsRetryable to tell transient from permanent failures.
+func WithRetries(n int) Option {
+	return func(w *worker) {
+		w.retries = n
+	}
+}
+
+// WithRetryNonIdempotent allows retrying requests with any method, e.g. POST.
+// Only use this if the server can handle duplicate requests.
+func WithRetryNonIdempotent() Option {
+	return func(w *worker) {
+		w.retryAny = true
+	}
+}
+
+// WithRetryTruncated also retries requests whose response was cut off, i.e.
+// failed to decode with an unexpected EOF, e.g. because of a flaky proxy. The
+// retry fetches the response again. Other decode errors, like a mismatch
+// between the JSON and Data(), are never retried. Requests implementing
+// WriterRequest or StreamRequest are not retried this way, since they already
+// consumed part of the body.
+func WithRetryTruncated() Option {
+	return func(w *worker) {
+		w.retryTruncated = true
+	}
+}
+
+// retryable reports whether r, sent as request, may be retried.
+func (w worker) retryable(r JSONRequest, request *http.Request) bool {
+	if _, ok := r.(RetryDecider); ok {
+		return true
+	}
+	if ir, ok := r.(IdempotentRequest); ok {
+		return ir.Idempotent()
+	}
+	return w.retryAny || idempotentMethod(request.Method) ||
+		request.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// WithFreshRequests creates the *http.Request of every retry by calling
+// Request() again, e.g. for requests that sign themselves with a fresh nonce.
+// By default, the first *http.Request is replayed, which avoids building and
+// encoding the request again. The context, conditions and Idempotency-Key
+// header carry over to the new request. Requests with a body that can't be
+// replayed are still not retried. Later pages of a PagedRequest are always
+// replayed.
+func WithFreshRequests() Option {
+	return func(w *worker) {
+		w.freshRequests = true
+	}
+}
+
+// rebuild creates a new *http.Request for the retry of r after failed. Like
+// for rewind, a body can only be sent again if failed had GetBody set, since
+// Request() might return a reader that was already consumed.
+func rebuild(r JSONRequest, failed *http.Request) (*http.Request, error) {
+	if failed.Body != nil && failed.Body != http.NoBody && failed.GetBody == nil {
+		return nil, errors.New("Request body can't be replayed")
+	}
+	request, err := buildRequest(failed.Context(), r)
+	if request == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("Request returned nil")
+	}
+	setConditions(r, request)
+	for _, key := range []string{IdempotencyKeyHeader, RequestIDHeader} {
+		if v := failed.Header.Get(key); v != "" && request.Header.Get(key) == "" {
+			request.Header.Set(key, v)
+		}
+	}
+	return request, nil
+}
+
+// shouldRetry reports whether r should be retried after the given failed
+// attempt.
+func (w worker) shouldRetry(r JSONRequest, resp *http.Response, err error, attempt int) bool {
+	if rd, ok := r.(RetryDecider); ok {
+		return rd.ShouldRetry(resp, err, attempt)
+	}
+	return IsRetryable(err) || w.retryTruncated && truncated(r, err)
+}
+
+// truncated reports whether err is a *DecodeError caused by a body that ended
+// too early, and r can decode the body again.
+func truncated(r JSONRequest, err error) bool {
+	switch r.(type) {
+	case WriterRequest, StreamRequest:
+		return false
+	}
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		return false
+	}
+	if errors.Is(de.Err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	// json.Unmarshal, as used by many Codecs, reports truncated input as a
+	// syntax error.
+	var se *json.SyntaxError
+	return errors.As(de.Err, &se) && se.Error() == "unexpected end of JSON input"
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// WithBackoff makes the workers wait between retries. The delay after the
+// n-th failed attempt, starting at 1, is base*factor^(n-1), but at most max, so
+// the first retry waits base.
+func WithBackoff(base time.Duration, factor float64, max time.Duration) Option {
+	return func(w *worker) {
+		w.backoff.base = base
+		w.backoff.factor = factor
+		w.backoff.max = max
+	}
+}
+
+// WithJitter randomizes each backoff delay by up to the given fraction, e.g.
+// 0.5 waits between 50% and 100% of the computed delay.
+func WithJitter(fraction float64) Option {
+	return func(w *worker) {
+		w.backoff.jitter = math.Max(0, math.Min(1, fraction))
+	}
+}
+
+// IsRetryable reports whether err is a transient failure, that may succeed if
+// the request is performed again.
+func IsRetryable(err error) bool {
+	var r interface{ Retryable() bool }
+	return errors.As(err, &r) && r.Retryable()
+}
+
+// isTransient reports whether err returned by http.Client.Do is caused by the
+// network rather than by the request itself.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		err = uerr.Err
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
+
+// rewind prepares request to be sent again. Requests with a body can only be
+// rewound if GetBody is set.
+func rewind(request *http.Request) (*http.Request, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return request, nil
+	}
+	if request.GetBody == nil {
+		return nil, errors.New("Request body can't be replayed")
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error replaying request body")
+	}
+	next := request.Clone(request.Context())
+	next.Body = body
+	return next, nil
+}
+
+type backoff struct {
+	base   time.Duration
+	factor float64
+	max    time.Duration
+	jitter float64
+}
+
+// delay returns the time to wait after the given failed attempt.
+func (b backoff) delay(attempt int) time.Duration {
+	d := float64(b.base) * math.Pow(b.factor, float64(attempt))
+	if b.max > 0 && d > float64(b.max) {
+		d = float64(b.max)
+	}
+	d -= d * b.jitter * rand.Float64()
+	return time.Duration(d)
+}
+
+// retryAfter returns the delay requested by the server that answered with err,
+// or zero.
+func retryAfter(err error) time.Duration {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.RetryAfter
+	}
+	return 0
+}
+
+// sleep waits for d, returning early with an error if ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package jsonrq
+
+import (
+	"sync"
+)
+
+// retryBudgetReserve is the number of retries a retry budget holds initially
+// and at most, so that a Pool that performed few requests can still retry.
+const retryBudgetReserve = 10
+
+// WithRetryBudget limits the retries of all workers of the Pool combined to
+// ratio times the number of requests performed, e.g. 0.1 allows one retry per
+// ten requests. Unused retries are saved up to a reserve of ten. Once the
+// budget is used up, failed requests are not retried but fail right away, so
+// that retries don't multiply the load on a server during an outage.
+func WithRetryBudget(ratio float64) Option {
+	return func(w *worker) {
+		w.retryBudget = &retryBudget{ratio: ratio, tokens: retryBudgetReserve}
+	}
+}
+
+// retryBudget is a token bucket filled by requests and drained by retries.
+type retryBudget struct {
+	ratio float64
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// deposit records a new request.
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, retryBudgetReserve)
+}
+
+// withdraw reports whether a retry is allowed and takes it from the budget.
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,41 @@
+package jsonrq
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// WithURLRewriter lets rewrite transform the URL of every request before it is
+// sent, e.g. to prepend a base URL or to pick the endpoint of a region, so that
+// requests don't need to know where they are sent to. rewrite is called with a
+// copy of the URL for every attempt, so retries can fail over to another
+// endpoint. If it returns nil, the URL is left unchanged. The Host header
+// follows the rewritten URL, unless the request set its own with SetHost.
+func WithURLRewriter(rewrite func(*url.URL) *url.URL) Option {
+	return func(w *worker) {
+		w.rewriteURL = rewrite
+	}
+}
+
+// rewrite returns a copy of request with the URL changed by the rewriter
+// set with WithURLRewriter. request itself is left as it is, so that the next
+// attempt starts from the original URL again.
+func (w worker) rewrite(request *http.Request) *http.Request {
+	if w.rewriteURL == nil {
+		return request
+	}
+	u := *request.URL
+	rewritten := w.rewriteURL(&u)
+	if rewritten == nil {
+		return request
+	}
+	// The rewritten URL is modified later on, e.g. by WithDefaultQuery, so
+	// it must not be shared with the rewriter.
+	u = *rewritten
+	next := *request
+	next.URL = &u
+	if request.Host == request.URL.Host {
+		next.Host = u.Host
+	}
+	return &next
+}
@@ -0,0 +1,93 @@
+package jsonrq
+
+import (
+	"io"
+	"net/http"
+	"os"
+)
+
+// SetSpoolBody writes the body to a temporary file when the request is first
+// created, and sends it from there. This makes bodies that are too large for
+// memory replayable for retries and redirects, e.g. multipart uploads of files
+// added with AddFormFile. The body is only read once, so requests created
+// again WithFreshRequests send the same file. The file is removed once a
+// worker is done with the request, whether it succeeded or not.
+func (r *BasicRequest) SetSpoolBody(spool bool) {
+	r.spoolBody = spool
+}
+
+// spooledBody is a request body stored in a temporary file.
+type spooledBody struct {
+	name        string
+	size        int64
+	contentType string
+	gzipped     bool
+}
+
+// spool encodes the body of r into a temporary file.
+func (r *BasicRequest) spool() error {
+	body, contentType, gzipped, err := r.encodeBody()
+	if err != nil {
+		return err
+	}
+	s := &spooledBody{contentType: contentType, gzipped: gzipped}
+	if body == nil {
+		r.spooled = s
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "jsonrq-body-*")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s.name = f.Name()
+	if s.size, err = io.Copy(f, body); err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		os.Remove(s.name)
+		return err
+	}
+	r.spooled = s
+	return nil
+}
+
+// open returns a new reader of the body.
+func (s *spooledBody) open() (io.Reader, error) {
+	if s.name == "" {
+		return nil, nil
+	}
+	if s.size == 0 {
+		return http.NoBody, nil
+	}
+	return os.Open(s.name)
+}
+
+func (s *spooledBody) reopen() (io.ReadCloser, error) {
+	body, err := s.open()
+	if err != nil || body == nil {
+		return http.NoBody, err
+	}
+	return body.(io.ReadCloser), nil
+}
+
+// spooler is implemented by BasicRequest and the types embedding it to remove
+// their temporary files.
+type spooler interface {
+	removeSpooled()
+}
+
+func (r *BasicRequest) removeSpooled() {
+	if r.spooled != nil && r.spooled.name != "" {
+		os.Remove(r.spooled.name)
+	}
+	r.spooled = nil
+}
+
+// removeSpooled removes the temporary body file of r, if any.
+func removeSpooled(r JSONRequest) {
+	if s, ok := r.(spooler); ok {
+		s.removeSpooled()
+	}
+}
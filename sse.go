@@ -0,0 +1,135 @@
+package jsonrq
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSEEvent is a single event of a text/event-stream.
+type SSEEvent struct {
+	// Type is the event type, "message" unless the event set another one.
+	Type string
+	// Data are the data lines of the event joined by newlines.
+	Data string
+	// ID is the last event ID seen so far in the stream.
+	ID string
+	// Retry is the reconnection time requested by the event, or zero.
+	Retry time.Duration
+}
+
+// SSERequest consumes a stream of server-sent events, calling a handler with
+// every event as soon as it is complete. The stream is read until the server
+// ends it or the request's context is done, so long-lived streams should not
+// be used with a client timeout. A non-nil error from the handler stops
+// reading and fails the request.
+type SSERequest struct {
+	BasicRequest
+	handle func(SSEEvent) error
+	lastID string
+}
+
+// NewSSERequest creates an SSERequest fetching url and calling handle with
+// every event.
+func NewSSERequest(url string, handle func(SSEEvent) error) *SSERequest {
+	r := &SSERequest{
+		BasicRequest: NewBasicRequest(url),
+		handle:       handle,
+	}
+	r.AddHeader("Accept", "text/event-stream")
+	return r
+}
+
+// Data returns nil, the events are parsed by Decode.
+func (r *SSERequest) Data() interface{} {
+	return nil
+}
+
+// Done does nothing.
+func (r *SSERequest) Done() {}
+
+// LastEventID returns the ID of the last event received, e.g. to resume the
+// stream with a Last-Event-ID header.
+func (r *SSERequest) LastEventID() string {
+	return r.lastID
+}
+
+// Decode parses the event stream as described in the HTML specification.
+func (r *SSERequest) Decode(body io.Reader) error {
+	sc := bufio.NewScanner(body)
+	sc.Buffer(nil, 1<<20)
+	sc.Split(scanSSELines)
+
+	var ev SSEEvent
+	var data strings.Builder
+	hasData := false
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if hasData {
+				ev.Data = data.String()
+				ev.ID = r.lastID
+				if ev.Type == "" {
+					ev.Type = "message"
+				}
+				if err := r.handle(ev); err != nil {
+					return err
+				}
+			}
+			ev = SSEEvent{}
+			data.Reset()
+			hasData = false
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Type = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				r.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				ev.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return sc.Err()
+}
+
+// scanSSELines splits lines ending in CRLF, LF or CR.
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	for i, b := range data {
+		switch b {
+		case '\n':
+			return i + 1, data[:i], nil
+		case '\r':
+			if i+1 == len(data) && !atEOF {
+				// A following LF belongs to this line ending.
+				return 0, nil, nil
+			}
+			if i+1 < len(data) && data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,89 @@
+package jsonrq
+
+import (
+	"sync/atomic"
+)
+
+// Stats is a snapshot of the requests processed by a Pool.
+type Stats struct {
+	// Processed counts the requests that are done.
+	Processed uint64
+	// Succeeded and Failed split Processed by whether Err() returned nil.
+	Succeeded uint64
+	Failed    uint64
+	// InFlight is the number of requests currently being processed.
+	InFlight uint64
+	// Workers describes the activity of each running worker, in no
+	// particular order.
+	Workers []WorkerStats
+	// Conns counts the connections used, if the Pool was created
+	// WithConnTrace.
+	Conns ConnStats
+}
+
+// counters are updated by the workers of a Pool.
+type counters struct {
+	succeeded atomic.Uint64
+	failed    atomic.Uint64
+	inFlight  atomic.Int64
+	// queued counts the requests scheduled but not yet picked up by a
+	// worker. It is decremented by the worker and may be briefly negative.
+	queued atomic.Int64
+
+	connNew     atomic.Uint64
+	connReused  atomic.Uint64
+	connWasIdle atomic.Uint64
+}
+
+func (c *counters) start() {
+	c.inFlight.Add(1)
+}
+
+func (c *counters) done(err error) {
+	if err != nil {
+		c.failed.Add(1)
+	} else {
+		c.succeeded.Add(1)
+	}
+	c.inFlight.Add(-1)
+}
+
+// Stats returns the current statistics of the Pool. The counters are read
+// individually without locking, so they are not guaranteed to be consistent
+// with each other while requests are being processed.
+func (p Pool) Stats() Stats {
+	c := p.w.counters
+	s := Stats{
+		Succeeded: c.succeeded.Load(),
+		Failed:    c.failed.Load(),
+	}
+	s.Processed = s.Succeeded + s.Failed
+	s.Workers = p.w.activity.stats()
+	s.Conns = ConnStats{
+		New:     c.connNew.Load(),
+		Reused:  c.connReused.Load(),
+		WasIdle: c.connWasIdle.Load(),
+	}
+	if n := c.inFlight.Load(); n > 0 {
+		s.InFlight = uint64(n)
+	}
+	return s
+}
+
+// QueueLen returns the number of requests that were scheduled but not yet
+// picked up by a worker, including requests held back WithPriority. Requests
+// blocked in Do waiting for buffer space are not counted.
+func (p Pool) QueueLen() int {
+	if n := p.w.counters.queued.Load(); n > 0 {
+		return int(n)
+	}
+	return 0
+}
+
+// Active returns the number of requests currently being processed.
+func (p Pool) Active() int {
+	if n := p.w.counters.inFlight.Load(); n > 0 {
+		return int(n)
+	}
+	return 0
+}
@@ -0,0 +1,18 @@
+package jsonrq
+
+// ExpectStatus can be implemented by a JSONRequest to declare which status
+// codes are successful, e.g. a 3xx code of an API that doesn't redirect.
+// Responses with acceptable codes are decoded, all others fail with a
+// *StatusError. By default, only 2xx codes are acceptable.
+type ExpectStatus interface {
+	JSONRequest
+	AcceptableStatus(code int) bool
+}
+
+// acceptable reports whether code is a successful status for r.
+func acceptable(r JSONRequest, code int) bool {
+	if es, ok := r.(ExpectStatus); ok {
+		return es.AcceptableStatus(code)
+	}
+	return code >= 200 && code <= 299
+}
@@ -0,0 +1,101 @@
+package jsonrq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// StreamRequest can be implemented by a JSONRequest to consume the response
+// incrementally from the json.Decoder, e.g. with DecodeArray, instead of
+// decoding it into Data() at once.
+type StreamRequest interface {
+	JSONRequest
+	Stream(dec *json.Decoder) error
+}
+
+// DecodeArray reads a JSON array from dec and calls elem for every element.
+// elem is expected to consume exactly one value, usually by calling
+// dec.Decode.
+func DecodeArray(dec *json.Decoder, elem func(dec *json.Decoder) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := elem(dec); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != want {
+		return errors.Errorf("Expected %v, got %v", want, t)
+	}
+	return nil
+}
+
+// ArrayStreamRequest decodes the elements of a top-level JSON array one by one
+// and sends them to a channel, so large arrays are consumed with constant
+// memory. Sending blocks until the element is received, or until the context
+// the request is performed with is done. The channel is never closed by the
+// request; the caller owns it.
+//
+// Elements already sent are sent again if a failed request is retried.
+type ArrayStreamRequest[T any] struct {
+	BasicRequest
+	out chan<- T
+	ctx context.Context
+}
+
+// StreamTo creates an ArrayStreamRequest fetching the array at url and sending
+// every element to out.
+func StreamTo[T any](url string, out chan<- T) *ArrayStreamRequest[T] {
+	return &ArrayStreamRequest[T]{BasicRequest: NewBasicRequest(url), out: out}
+}
+
+// RequestWithContext prepares the request and keeps ctx to abort sending
+// elements once it is done.
+func (r *ArrayStreamRequest[T]) RequestWithContext(ctx context.Context) (*http.Request, error) {
+	r.ctx = ctx
+	request := r.Request()
+	if request == nil {
+		return nil, r.Err()
+	}
+	return request, nil
+}
+
+// Data returns nil, the elements are decoded by Stream.
+func (r *ArrayStreamRequest[T]) Data() interface{} {
+	return nil
+}
+
+// Stream decodes the array and sends every element to the channel.
+func (r *ArrayStreamRequest[T]) Stream(dec *json.Decoder) error {
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return DecodeArray(dec, func(dec *json.Decoder) error {
+		var elem T
+		if err := dec.Decode(&elem); err != nil {
+			return err
+		}
+		select {
+		case r.out <- elem:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// Done does nothing.
+func (r *ArrayStreamRequest[T]) Done() {}
@@ -0,0 +1,35 @@
+package jsonrq
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+)
+
+// withTLS adds a setting to the TLS configuration of the Pool's transport.
+func withTLS(set func(c *tls.Config)) Option {
+	return withTransport(func(t *http.Transport) {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		} else {
+			t.TLSClientConfig = t.TLSClientConfig.Clone()
+		}
+		set(t.TLSClientConfig)
+	})
+}
+
+// WithClientCert presents cert to servers that ask for a client certificate,
+// e.g. for mutual TLS. It may be used multiple times to add more certificates.
+func WithClientCert(cert tls.Certificate) Option {
+	return withTLS(func(c *tls.Config) {
+		c.Certificates = append(c.Certificates, cert)
+	})
+}
+
+// WithRootCAs verifies server certificates against pool instead of the
+// system's root certificates.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return withTLS(func(c *tls.Config) {
+		c.RootCAs = pool
+	})
+}
@@ -0,0 +1,28 @@
+package jsonrq
+
+import (
+	"context"
+)
+
+// Tracer starts a span around every attempt to perform a request. The
+// returned context is used for the request, so it can be propagated to the
+// server by the transport. finish is called with the outcome once the attempt
+// is done.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (_ context.Context, finish func(SpanInfo))
+}
+
+// SpanInfo describes the outcome of a traced attempt.
+type SpanInfo struct {
+	URL string
+	// Status is the status code of the response, or zero if there was none.
+	Status int
+	Err    error
+}
+
+// WithTracer traces all requests with t.
+func WithTracer(t Tracer) Option {
+	return func(w *worker) {
+		w.tracer = t
+	}
+}
@@ -0,0 +1,30 @@
+package jsonrq
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// TrailerReceiver can be implemented by a JSONRequest to receive the trailers
+// of a successful response. Trailers are only sent after the body, so the rest
+// of the body is read and discarded after decoding it.
+type TrailerReceiver interface {
+	JSONRequest
+	SetTrailers(trailer http.Header)
+}
+
+// readTrailers passes the trailers of resp to r, if it is a TrailerReceiver,
+// after consuming the rest of body.
+func readTrailers(r JSONRequest, resp *http.Response, body io.Reader) error {
+	tr, ok := r.(TrailerReceiver)
+	if !ok {
+		return nil
+	}
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return errors.Wrap(err, "HTTP: Error reading response trailers")
+	}
+	tr.SetTrailers(resp.Trailer)
+	return nil
+}
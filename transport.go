@@ -0,0 +1,157 @@
+package jsonrq
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// withTransport adds a setting to the *http.Transport of the Pool's client.
+func withTransport(set func(t *http.Transport)) Option {
+	return func(w *worker) {
+		w.transport = append(w.transport, set)
+	}
+}
+
+// withClient adds a setting to the Pool's client.
+func withClient(set func(c *http.Client)) Option {
+	return func(w *worker) {
+		w.clientSettings = append(w.clientSettings, set)
+	}
+}
+
+// configureClient applies the client and transport settings to a copy of the
+// client and its transport, so a shared client like http.DefaultClient is
+// never modified. The transport settings are ignored if the client uses a
+// RoundTripper other than *http.Transport.
+func (w *worker) configureClient() {
+	if set := w.dialSetting(); set != nil {
+		w.transport = append(w.transport, set)
+	}
+	if len(w.transport) == 0 && len(w.clientSettings) == 0 {
+		return
+	}
+
+	client := *w.client
+	if len(w.transport) > 0 {
+		var t *http.Transport
+		switch rt := client.Transport.(type) {
+		case nil:
+			t = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			t = rt.Clone()
+		}
+		if t != nil {
+			for _, set := range w.transport {
+				set(t)
+			}
+			client.Transport = t
+		}
+	}
+	for _, set := range w.clientSettings {
+		set(&client)
+	}
+	w.client = &client
+}
+
+// TransportOptions tune the connections of a Pool created with NewPoolTuned.
+// Zero values keep the settings of http.DefaultTransport.
+type TransportOptions struct {
+	// MaxIdleConns limits the idle connections across all hosts.
+	MaxIdleConns int
+	// MaxConnsPerHost limits the connections per host, including
+	// connections in use.
+	MaxConnsPerHost int
+	// IdleConnTimeout closes idle connections after this duration.
+	IdleConnTimeout time.Duration
+	// ForceHTTP2 attempts HTTP/2 even though custom dialers or TLS settings
+	// are configured.
+	ForceHTTP2 bool
+}
+
+func (o TransportOptions) apply(t *http.Transport) {
+	if o.MaxIdleConns > 0 {
+		t.MaxIdleConns = o.MaxIdleConns
+	}
+	if o.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = o.MaxConnsPerHost
+	}
+	if o.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = o.IdleConnTimeout
+	}
+	if o.ForceHTTP2 {
+		t.ForceAttemptHTTP2 = true
+	}
+}
+
+// NewPoolTuned creates a new Pool with n workers, that use a transport tuned
+// by topts. It can be combined with all other options, including other
+// transport settings.
+func NewPoolTuned(n uint, topts TransportOptions, opts ...Option) Pool {
+	return NewPool(n, append([]Option{withTransport(topts.apply)}, opts...)...)
+}
+
+// WithRedirectPolicy sets the CheckRedirect policy of the Pool's client. See
+// http.Client for details.
+func WithRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) Option {
+	return withClient(func(c *http.Client) {
+		c.CheckRedirect = policy
+	})
+}
+
+// WithNoRedirects stops following redirects. The redirect response itself is
+// treated like any other non-2xx response.
+func WithNoRedirects() Option {
+	return WithRedirectPolicy(func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+}
+
+// WithProxy sends all requests through the proxy at proxyURL. If proxyURL is
+// invalid, all requests fail.
+func WithProxy(proxyURL string) Option {
+	u, err := url.Parse(proxyURL)
+	proxy := http.ProxyURL(u)
+	if err != nil {
+		proxy = func(*http.Request) (*url.URL, error) {
+			return nil, errors.Wrap(err, "Invalid proxy URL")
+		}
+	}
+	return withTransport(func(t *http.Transport) {
+		t.Proxy = proxy
+	})
+}
+
+// WithEnvProxy sends requests through the proxy configured by the environment
+// variables HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+func WithEnvProxy() Option {
+	return withTransport(func(t *http.Transport) {
+		t.Proxy = http.ProxyFromEnvironment
+	})
+}
+
+// WithDisableKeepAlives uses every connection for a single request only, for
+// servers that mishandle persistent connections.
+func WithDisableKeepAlives() Option {
+	return withTransport(func(t *http.Transport) {
+		t.DisableKeepAlives = true
+	})
+}
+
+// WithMaxIdleConnsPerHost keeps up to n idle connections per host for reuse.
+// The default of http.Transport is 2, which causes needless reconnects if more
+// workers talk to the same host.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return withTransport(func(t *http.Transport) {
+		t.MaxIdleConnsPerHost = n
+	})
+}
+
+// CloseIdleConnections closes the idle connections of the Pool's transport,
+// e.g. before a long idle period or to reconnect after DNS changes.
+// Connections in use are not interrupted.
+func (p Pool) CloseIdleConnections() {
+	p.w.client.CloseIdleConnections()
+}
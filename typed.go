@@ -0,0 +1,25 @@
+package jsonrq
+
+// TypedRequest is a JSONRequest that decodes the response into a T.
+type TypedRequest[T any] struct {
+	BasicRequest
+	result T
+}
+
+// NewTypedRequest creates a TypedRequest fetching url.
+func NewTypedRequest[T any](url string) *TypedRequest[T] {
+	return &TypedRequest[T]{BasicRequest: NewBasicRequest(url)}
+}
+
+// Data returns a pointer to the result.
+func (r *TypedRequest[T]) Data() interface{} {
+	return &r.result
+}
+
+// Done does nothing. Read the Result once the request was processed.
+func (r *TypedRequest[T]) Done() {}
+
+// Result returns the decoded response.
+func (r *TypedRequest[T]) Result() T {
+	return r.result
+}
@@ -0,0 +1,53 @@
+package jsonrq
+
+import (
+	"io"
+	"net/http"
+)
+
+// UploadProgress can be implemented by a JSONRequest to follow the upload of
+// its body. OnUploadProgress is called from the transport each time a chunk of
+// the body was sent, with the bytes sent so far and the Content-Length of the
+// body, or -1 if it is unknown. Every attempt uploads the body again, so sent
+// starts over from 0 when a request is retried.
+type UploadProgress interface {
+	JSONRequest
+	OnUploadProgress(sent, total int64)
+}
+
+// trackUpload wraps the body of request to report its progress to r, if r
+// implements UploadProgress.
+func trackUpload(r JSONRequest, request *http.Request) *http.Request {
+	up, ok := r.(UploadProgress)
+	if !ok || request.Body == nil || request.Body == http.NoBody {
+		return request
+	}
+	total := request.ContentLength
+	if total == 0 {
+		total = -1
+	}
+	tracked := *request
+	tracked.Body = &progressBody{rc: request.Body, up: up, total: total}
+	return &tracked
+}
+
+// progressBody reports the bytes read from rc to up.
+type progressBody struct {
+	rc    io.ReadCloser
+	up    UploadProgress
+	sent  int64
+	total int64
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if n > 0 {
+		b.sent += int64(n)
+		b.up.OnUploadProgress(b.sent, b.total)
+	}
+	return n, err
+}
+
+func (b *progressBody) Close() error {
+	return b.rc.Close()
+}
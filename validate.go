@@ -0,0 +1,10 @@
+package jsonrq
+
+// Validator can be implemented by a JSONRequest to check the decoded data,
+// e.g. for required fields. Validate is called once the response, including
+// all pages, was decoded successfully. A non-nil error fails the request
+// without retrying it.
+type Validator interface {
+	JSONRequest
+	Validate() error
+}
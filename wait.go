@@ -0,0 +1,41 @@
+package jsonrq
+
+import (
+	"sync"
+)
+
+// pending counts the requests scheduled on a Pool that are not yet done.
+type pending struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	n    int
+}
+
+func newPending() *pending {
+	p := new(pending)
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+func (p *pending) add(delta int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.n += delta
+	if p.n <= 0 {
+		p.cond.Broadcast()
+	}
+}
+
+func (p *pending) wait() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.n > 0 {
+		p.cond.Wait()
+	}
+}
+
+// Wait blocks until all requests scheduled so far are done, without stopping
+// the Pool. Requests scheduled while waiting are waited for as well.
+func (p Pool) Wait() {
+	p.w.pending.wait()
+}
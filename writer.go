@@ -0,0 +1,20 @@
+package jsonrq
+
+import (
+	"io"
+)
+
+// WriterRequest can be implemented by a JSONRequest to stream the response
+// body to a writer, e.g. a file, instead of decoding it. Data() is not used.
+// If a failed request is retried, the body of every attempt is written, so a
+// writer that can't be rewound should be used without retries.
+type WriterRequest interface {
+	JSONRequest
+	Writer() io.Writer
+}
+
+// writeBody copies body to the writer of wr.
+func writeBody(wr WriterRequest, body io.Reader) error {
+	_, err := io.Copy(wr.Writer(), body)
+	return newDecodeError(err, "HTTP: Error writing response")
+}